@@ -0,0 +1,103 @@
+package zkverifier_kit
+
+import (
+	"context"
+	"sync"
+
+	zkptypes "github.com/iden3/go-rapidsnark/types"
+)
+
+// Result is an outcome of a single proof verification in Verifier.VerifyProofs
+type Result struct {
+	// Err is nil when the proof is valid, otherwise it is the same error that
+	// Verifier.VerifyProof would return
+	Err error
+}
+
+// VerifyProofs verifies the batch of proofs in parallel with the bounded amount
+// of workers, see WithBatchWorkers. Each distinct IdStateRoot is verified only
+// once per batch. The results are returned in the same order as the proofs.
+//
+// When the context is canceled, the remaining proofs are not verified and their
+// results contain the context error.
+func (v *Verifier) VerifyProofs(ctx context.Context, proofs []zkptypes.ZKProof, options ...VerifyOption) []Result {
+	results := make([]Result, len(proofs))
+	v2 := Verifier{
+		verificationKey: v.verificationKey,
+		opts:            mergeOptions(false, v.opts, options...),
+	}
+
+	if len(options) > 0 {
+		if err := v2.opts.validate(); err != nil {
+			for i := range results {
+				results[i].Err = err
+			}
+			return results
+		}
+	}
+
+	v2.opts.rootVerifier = &batchRootVerifier{
+		next:  v2.opts.rootVerifier,
+		roots: make(map[string]*rootResult),
+	}
+
+	workers := min(max(v2.opts.batchWorkers, 1), len(proofs))
+	jobs := make(chan int)
+	wg := new(sync.WaitGroup)
+
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i].Err = v2.verify(ctx, proofs[i])
+			}
+		}()
+	}
+
+	for i := range proofs {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+		}
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// batchRootVerifier verifies each distinct root once, sharing the result
+// between all the proofs of the batch
+type batchRootVerifier struct {
+	next  IdentityRootVerifier
+	mu    sync.Mutex
+	roots map[string]*rootResult
+}
+
+type rootResult struct {
+	once sync.Once
+	err  error
+}
+
+func (b *batchRootVerifier) VerifyRoot(root string) error {
+	return b.VerifyRootContext(context.Background(), root)
+}
+
+func (b *batchRootVerifier) VerifyRootContext(ctx context.Context, root string) error {
+	b.mu.Lock()
+	res, ok := b.roots[root]
+	if !ok {
+		res = new(rootResult)
+		b.roots[root] = res
+	}
+	b.mu.Unlock()
+
+	res.once.Do(func() {
+		res.err = b.next.VerifyRootContext(ctx, root)
+	})
+
+	return res.err
+}
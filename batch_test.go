@@ -0,0 +1,126 @@
+package zkverifier_kit
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	zkptypes "github.com/iden3/go-rapidsnark/types"
+	"github.com/rarimo/zkverifier-kit/identity"
+	"github.com/rarimo/zkverifier-kit/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingRootVerifier struct {
+	calls atomic.Int32
+}
+
+func (v *countingRootVerifier) VerifyRoot(root string) error {
+	return v.VerifyRootContext(context.Background(), root)
+}
+
+func (v *countingRootVerifier) VerifyRootContext(_ context.Context, root string) error {
+	v.calls.Add(1)
+	if root != validProof.PubSignals[IdStateRoot] {
+		return identity.ErrInvalidRoot
+	}
+	return nil
+}
+
+func TestVerifyProofs(t *testing.T) {
+	invalidRoot := validProof
+	invalidRoot.PubSignals = append([]string{}, validProof.PubSignals...)
+	invalidRoot.PubSignals[IdStateRoot] = "1"
+
+	noSignals := zkptypes.ZKProof{Proof: validProof.Proof}
+
+	proofs := []zkptypes.ZKProof{validProof, noSignals, invalidRoot, validProof, invalidRoot, validProof}
+	want := []string{
+		"groth16 verification failed",
+		"zk_proof/pub_signals: cannot be blank",
+		"pub_signals/id_state_root: invalid identity root",
+		"groth16 verification failed",
+		"pub_signals/id_state_root: invalid identity root",
+		"groth16 verification failed",
+	}
+
+	// groth16 verification always fails with this key, so that the results of
+	// proofs with valid signals are distinguishable
+	invalidKey := mismatchedKey(t, 1)
+
+	rv := new(countingRootVerifier)
+	verifier, err := NewPassportVerifier(invalidKey,
+		WithNow(proofDate),
+		WithProofSelectorValue(validSelector),
+		WithIdentityVerifier(rv),
+		WithBatchWorkers(3),
+	)
+	require.NoError(t, err)
+
+	results := verifier.VerifyProofs(context.Background(), proofs)
+	require.Len(t, results, len(proofs))
+	for i, res := range results {
+		assert.ErrorContains(t, res.Err, want[i], i)
+	}
+	// each distinct root is verified once
+	assert.Equal(t, int32(2), rv.calls.Load())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, res := range verifier.VerifyProofs(ctx, proofs) {
+		assert.Error(t, res.Err)
+	}
+
+	assert.Empty(t, verifier.VerifyProofs(context.Background(), nil))
+}
+
+// countingCaller counts the contract calls of identity.Verifier
+type countingCaller struct {
+	*testutil.MockCaller
+	calls atomic.Int32
+}
+
+func (c *countingCaller) IsRootValid(opts *bind.CallOpts, root [32]byte) (bool, error) {
+	c.calls.Add(1)
+	return c.MockCaller.IsRootValid(opts, root)
+}
+
+func TestVerifyProofs_Valid(t *testing.T) {
+	withSignal := func(signal PubSignal, value string) []string {
+		signals := slices.Clone(validProof.PubSignals)
+		signals[signal] = value
+		return signals
+	}
+
+	// the key depends only on the amount of signals, so all the proofs are
+	// valid for it
+	key, valid := testutil.Groth16Proof(validProof.PubSignals)
+	_, otherNullifier := testutil.Groth16Proof(withSignal(Nullifier, "1"))
+	_, otherRoot := testutil.Groth16Proof(withSignal(IdStateRoot, "1"))
+
+	caller := &countingCaller{MockCaller: new(testutil.MockCaller).WithRoot(storedRoot)}
+	verifier, err := NewPassportVerifier(key,
+		WithNow(proofDate),
+		WithProofSelectorValue(validSelector),
+		WithIdentityVerifier(identity.NewVerifier(caller, 0)),
+		WithBatchWorkers(3),
+	)
+	require.NoError(t, err)
+
+	proofs := []zkptypes.ZKProof{valid, otherNullifier, otherRoot, valid, otherRoot, otherNullifier}
+	results := verifier.VerifyProofs(context.Background(), proofs)
+	require.Len(t, results, len(proofs))
+	for i, res := range results {
+		if proofs[i].PubSignals[IdStateRoot] == "1" {
+			assert.True(t, errors.Is(res.Err, identity.ErrInvalidRoot), i)
+			continue
+		}
+		assert.NoError(t, res.Err, i)
+	}
+	// each distinct root is verified once
+	assert.Equal(t, int32(2), caller.calls.Load())
+}
@@ -0,0 +1,155 @@
+package zkverifier_kit
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// PassportClaims is a typed representation of the proof public signals. The
+// optional fields are nil when the proof does not reveal them: either the
+// selector disables the field, or the circuit sets the "not revealed" value.
+type PassportClaims struct {
+	Nullifier   *big.Int     `json:"nullifier"`
+	EventID     *big.Int     `json:"event_id"`
+	EventData   *big.Int     `json:"event_data"`
+	IdStateRoot *big.Int     `json:"id_state_root"`
+	Selector    SelectorMask `json:"selector"`
+
+	// Citizenship is an Alpha-3 country code, described in ISO 3166. The
+	// passport codes of ICAO Doc 9303 are normalized, e.g. "D" to "DEU", and
+	// CitizenshipName is set for the known codes.
+	Citizenship              *string    `json:"citizenship,omitempty"`
+	CitizenshipName          *string    `json:"citizenship_name,omitempty"`
+	BirthDate                *time.Time `json:"birth_date,omitempty"`
+	BirthDateLowerBound      *time.Time `json:"birth_date_lower_bound,omitempty"`
+	BirthDateUpperBound      *time.Time `json:"birth_date_upper_bound,omitempty"`
+	ExpirationDate           *time.Time `json:"expiration_date,omitempty"`
+	ExpirationDateLowerBound *time.Time `json:"expiration_date_lower_bound,omitempty"`
+
+	// TimestampUpperBound is a unix timestamp
+	TimestampUpperBound       *int64 `json:"timestamp_upper_bound,omitempty"`
+	IdentityCounterUpperBound *int64 `json:"identity_counter_upper_bound,omitempty"`
+}
+
+// ParsePubSignals decodes the signals with the default layout at the current
+// time, see SignalLayout.ParsePubSignalsAt
+func ParsePubSignals(signals []string) (PassportClaims, error) {
+	return ParsePubSignalsAt(signals, time.Now())
+}
+
+// ParsePubSignalsAt decodes the signals with the default layout, see
+// SignalLayout.ParsePubSignalsAt
+func ParsePubSignalsAt(signals []string, now time.Time) (PassportClaims, error) {
+	return DefaultSignalLayout().ParsePubSignalsAt(signals, now)
+}
+
+// ParsePubSignals decodes the signals at the current time, see
+// ParsePubSignalsAt
+func (l SignalLayout) ParsePubSignals(signals []string) (PassportClaims, error) {
+	return l.ParsePubSignalsAt(signals, time.Now())
+}
+
+// ParsePubSignalsAt decodes the signals into PassportClaims. It only checks
+// the signals format, use Verifier.VerifyProof to validate the values. The
+// dates are resolved relative to now, see DecodeBirthDate and
+// DecodeExpirationDate.
+func (l SignalLayout) ParsePubSignalsAt(signals []string, now time.Time) (PassportClaims, error) {
+	var (
+		claims PassportClaims
+		err    error
+	)
+
+	if len(signals) != l.Count {
+		return claims, fmt.Errorf("invalid signals count: got %d, expected %d", len(signals), l.Count)
+	}
+	signals = l.arrange(signals)
+
+	if claims.Selector, err = ParseSelectorMask(signals[Selector]); err != nil {
+		return claims, fmt.Errorf("%s: %w", Selector, err)
+	}
+
+	for s, dst := range map[PubSignal]**big.Int{
+		Nullifier:   &claims.Nullifier,
+		EventID:     &claims.EventID,
+		EventData:   &claims.EventData,
+		IdStateRoot: &claims.IdStateRoot,
+	} {
+		if *dst, err = parseBigInt(signals[s]); err != nil {
+			return claims, fmt.Errorf("%s: %w", s, err)
+		}
+	}
+
+	if claims.Selector.Has(SelectorCitizenship) && signals[Citizenship] != "0" {
+		if _, err = parseBigInt(signals[Citizenship]); err != nil {
+			return claims, fmt.Errorf("%s: %w", Citizenship, err)
+		}
+		citizenship := decodeInt(signals[Citizenship])
+		if c, ok := LookupCountry(citizenship); ok {
+			citizenship = c.Alpha3
+			claims.CitizenshipName = &c.Name
+		}
+		claims.Citizenship = &citizenship
+	}
+
+	var (
+		birth      = birthDateDecoder(now.UTC())
+		expiration = expirationDateDecoder(now.UTC())
+	)
+	for s, dst := range map[PubSignal]struct {
+		ptr    **time.Time
+		decode zkDateDecoder
+	}{
+		BirthDate:                {&claims.BirthDate, birth},
+		BirthdateLowerBound:      {&claims.BirthDateLowerBound, birth},
+		BirthdateUpperBound:      {&claims.BirthDateUpperBound, birth},
+		ExpirationDate:           {&claims.ExpirationDate, expiration},
+		ExpirationDateLowerBound: {&claims.ExpirationDateLowerBound, expiration},
+	} {
+		// the optional signals are empty when missing in layout
+		if signals[s] == "" || IsEmptyZKDate(signals[s]) {
+			continue
+		}
+
+		date, err := dst.decode(signals[s])
+		if err != nil {
+			return claims, fmt.Errorf("%s: %w", s, err)
+		}
+		*dst.ptr = &date
+	}
+
+	for s, dst := range map[PubSignal]struct {
+		ptr   **int64
+		field SelectorField
+	}{
+		TimestampUpperBound:       {&claims.TimestampUpperBound, SelectorTimestampUpperBound},
+		IdentityCounterUpperBound: {&claims.IdentityCounterUpperBound, SelectorIdentityCounterUpperBound},
+	} {
+		if !claims.Selector.Has(dst.field) {
+			continue
+		}
+
+		n, err := strconv.ParseInt(signals[s], 10, 64)
+		if err != nil {
+			return claims, fmt.Errorf("%s: %w", s, err)
+		}
+		*dst.ptr = &n
+	}
+
+	return claims, nil
+}
+
+// ParsePubSignals decodes the signals with the layout of the verifier, the
+// dates are resolved relative to its clock, see WithClock
+func (v *Verifier) ParsePubSignals(signals []string) (PassportClaims, error) {
+	return v.opts.layout.ParsePubSignalsAt(signals, v.now())
+}
+
+func parseBigInt(s string) (*big.Int, error) {
+	b, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid decimal integer %q", s)
+	}
+	return b, nil
+}
@@ -0,0 +1,90 @@
+package zkverifier_kit
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePubSignals(t *testing.T) {
+	claims, err := ParsePubSignalsAt(validProof.PubSignals, proofDate)
+	require.NoError(t, err)
+
+	assert.Equal(t, validProof.PubSignals[Nullifier], claims.Nullifier.String())
+	assert.Equal(t, validEventID, claims.EventID.String())
+	assert.Equal(t, validEventData, claims.EventData.Bytes())
+	assert.Equal(t, validProof.PubSignals[IdStateRoot], claims.IdStateRoot.String())
+	assert.Equal(t, SelectorMask(23073), claims.Selector)
+
+	require.NotNil(t, claims.Citizenship)
+	assert.Equal(t, ukrCitizenship, *claims.Citizenship)
+	require.NotNil(t, claims.CitizenshipName)
+	assert.Equal(t, "Ukraine", *claims.CitizenshipName)
+
+	assert.Nil(t, claims.BirthDate)
+	assert.Nil(t, claims.ExpirationDate)
+	require.NotNil(t, claims.BirthDateUpperBound)
+	assert.Equal(t, time.Date(2006, 5, 24, 0, 0, 0, 0, time.UTC), *claims.BirthDateUpperBound)
+	require.NotNil(t, claims.ExpirationDateLowerBound)
+	assert.Equal(t, time.Date(2024, 5, 24, 0, 0, 0, 0, time.UTC), *claims.ExpirationDateLowerBound)
+
+	require.NotNil(t, claims.TimestampUpperBound)
+	assert.Equal(t, int64(1713436478), *claims.TimestampUpperBound)
+	require.NotNil(t, claims.IdentityCounterUpperBound)
+	assert.Equal(t, int64(1), *claims.IdentityCounterUpperBound)
+
+	// the birth date bound is in the past at the current time as well
+	claims, err = ParsePubSignals(validProof.PubSignals)
+	require.NoError(t, err)
+	require.NotNil(t, claims.BirthDateUpperBound)
+	assert.Equal(t, time.Date(2006, 5, 24, 0, 0, 0, 0, time.UTC), *claims.BirthDateUpperBound)
+}
+
+func TestParsePubSignals_Invalid(t *testing.T) {
+	testCases := []struct {
+		name   string
+		signal PubSignal
+		value  string
+		want   string
+	}{
+		{name: "Invalid nullifier", signal: Nullifier, value: "0x01", want: "nullifier: invalid decimal integer"},
+		{name: "Invalid selector", signal: Selector, value: "abc", want: "selector: invalid selector"},
+		{name: "Invalid date", signal: BirthdateUpperBound, value: "1", want: "birth_date_upper_bound: invalid date string"},
+		{name: "Invalid counter", signal: IdentityCounterUpperBound, value: "1.5", want: "identity_counter_upper_bound"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			signals := slices.Clone(validProof.PubSignals)
+			signals[tc.signal] = tc.value
+
+			_, err := ParsePubSignalsAt(signals, proofDate)
+			assert.ErrorContains(t, err, tc.want)
+		})
+	}
+
+	_, err := ParsePubSignals(validProof.PubSignals[:10])
+	assert.ErrorContains(t, err, "invalid signals count")
+}
+
+func TestVerifier_ParsePubSignals(t *testing.T) {
+	signals := slices.Clone(validProof.PubSignals)
+	signals[BirthDate] = EncodeZKDate(time.Date(1955, 7, 1, 0, 0, 0, 0, time.UTC))
+
+	// the century of the birth date depends on the clock of the verifier
+	for now, want := range map[time.Time]time.Time{
+		proofDate: time.Date(1955, 7, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2060, 1, 1, 0, 0, 0, 0, time.UTC): time.Date(2055, 7, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		verifier, err := NewPassportVerifier(verificationKey, WithNow(now))
+		require.NoError(t, err)
+
+		claims, err := verifier.ParsePubSignals(signals)
+		require.NoError(t, err)
+		require.NotNil(t, claims.BirthDate)
+		assert.Equal(t, want, *claims.BirthDate, now)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	kit "github.com/rarimo/zkverifier-kit"
+	"github.com/rarimo/zkverifier-kit/identity"
+	"github.com/rarimo/zkverifier-kit/observer"
+	"gitlab.com/distributed_lab/figure/v3"
+	"gitlab.com/distributed_lab/kit/comfig"
+	"gitlab.com/distributed_lab/kit/kv"
+)
+
+// config collects the service dependencies from kv config:
+//
+//	listener:
+//	  addr: :8000
+//	verifier:
+//	  verification_key_file: key.json
+//	  verification_key_reload: 30s
+//	  verification_key_hash: ddd355c346e9e72922b92a5f63b734c62382ccd62a48d9b8a93e5e363f6979cd
+//	  proof_selector: "23073"
+//	  age: 18
+//	  citizenships: ["UKR"]
+//	  citizenships_excluded: ["EU"]
+//	  event_id: "304358862882731539112827930982999386691702727710421481944329166126417129570"
+//	  identities_counter: 1
+//	  identities_timestamp_limit: 1847321000
+//	root_verifier:
+//	  rpc: https://your-rpc
+//	  contract: 0x...
+//	  request_timeout: 10s
+type config struct {
+	comfig.Listenerer
+	identity.VerifierProvider
+
+	getter      kv.Getter
+	once        comfig.Once
+	metricsOnce comfig.Once
+}
+
+func newConfig(getter kv.Getter) *config {
+	return &config{
+		Listenerer:       comfig.NewListenerer(getter),
+		VerifierProvider: identity.NewVerifierProvider(getter),
+		getter:           getter,
+	}
+}
+
+// Verifier creates the proof verifier with options from `verifier` section and
+// the cached identity root verifier
+func (c *config) Verifier() *kit.Verifier {
+	return c.once.Do(func() interface{} {
+		var cfg struct {
+			VerificationKeyFile      string   `fig:"verification_key_file,required"`
+			VerificationKeyHash      string   `fig:"verification_key_hash"`
+			ProofSelector            string   `fig:"proof_selector,required"`
+			Age                      *int     `fig:"age"`
+			Citizenships             []string `fig:"citizenships"`
+			CitizenshipsExcluded     []string `fig:"citizenships_excluded"`
+			EventID                  string   `fig:"event_id"`
+			IdentitiesCounter        *int64   `fig:"identities_counter"`
+			IdentitiesTimestampLimit *int64   `fig:"identities_timestamp_limit"`
+		}
+
+		err := figure.Out(&cfg).
+			From(kv.MustGetStringMap(c.getter, "verifier")).
+			Please()
+		if err != nil {
+			panic(fmt.Errorf("failed to figure out verifier: %w", err))
+		}
+
+		opts := []kit.VerifyOption{
+			kit.WithVerificationKeyFile(cfg.VerificationKeyFile),
+			kit.WithVerificationKeyHash(cfg.VerificationKeyHash),
+			kit.WithProofSelectorValue(cfg.ProofSelector),
+			kit.WithEventID(cfg.EventID),
+			kit.WithIdentityVerifier(c.ProvideCachedVerifier()),
+			kit.WithObserver(c.Metrics()),
+		}
+		if cfg.Age != nil {
+			opts = append(opts, kit.WithAgeAbove(*cfg.Age))
+		}
+		if len(cfg.Citizenships) > 0 {
+			opts = append(opts, kit.WithCitizenships(cfg.Citizenships...))
+		}
+		if len(cfg.CitizenshipsExcluded) > 0 {
+			opts = append(opts, kit.WithCitizenshipsExcluded(cfg.CitizenshipsExcluded...))
+		}
+		if cfg.IdentitiesCounter != nil {
+			opts = append(opts, kit.WithIdentitiesCounter(*cfg.IdentitiesCounter))
+		}
+		if cfg.IdentitiesTimestampLimit != nil {
+			opts = append(opts, kit.WithIdentitiesCreationTimestampLimit(*cfg.IdentitiesTimestampLimit))
+		}
+
+		v, err := kit.NewPassportVerifier(nil, opts...)
+		if err != nil {
+			panic(fmt.Errorf("failed to create verifier: %w", err))
+		}
+
+		return v
+	}).(*kit.Verifier)
+}
+
+// Metrics returns the collector of verification metrics, which is observing
+// the Verifier
+func (c *config) Metrics() *observer.Prometheus {
+	return c.metricsOnce.Do(func() interface{} {
+		return observer.NewPrometheus("zkverifier")
+	}).(*observer.Prometheus)
+}
+
+// KeyReloadInterval returns the polling interval of the verification key file
+// from `verifier` section, zero disables reloading
+func (c *config) KeyReloadInterval() time.Duration {
+	var cfg struct {
+		KeyReload time.Duration `fig:"verification_key_reload"`
+	}
+
+	err := figure.Out(&cfg).
+		From(kv.MustGetStringMap(c.getter, "verifier")).
+		Please()
+	if err != nil {
+		panic(fmt.Errorf("failed to figure out verifier: %w", err))
+	}
+
+	return cfg.KeyReload
+}
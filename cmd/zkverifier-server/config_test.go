@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	zkptypes "github.com/iden3/go-rapidsnark/types"
+	kit "github.com/rarimo/zkverifier-kit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/distributed_lab/kit/kv"
+)
+
+func mapGetter(raw map[string]map[string]interface{}) kv.Getter {
+	return kv.GetterFunc(func(key string) (map[string]interface{}, error) {
+		return raw[key], nil
+	})
+}
+
+func TestConfig_Verifier(t *testing.T) {
+	raw, err := os.ReadFile("testdata/proof.json")
+	require.NoError(t, err)
+	var proof zkptypes.ZKProof
+	require.NoError(t, json.Unmarshal(raw, &proof))
+
+	cfg := newConfig(mapGetter(map[string]map[string]interface{}{
+		"verifier": {
+			"verification_key_file":   "../../example_verification_key.json",
+			"verification_key_reload": "30s",
+			"proof_selector":          "23073",
+			"citizenships":            []interface{}{"USA"},
+			"identities_counter":      1,
+		},
+		"root_verifier": {"disabled": true},
+	}))
+
+	assert.Equal(t, 30*time.Second, cfg.KeyReloadInterval())
+
+	verifier := cfg.Verifier()
+	assert.Same(t, verifier, cfg.Verifier())
+
+	err = verifier.VerifyProof(proof)
+	assert.True(t, errors.Is(err, kit.CodeCitizenshipNotAllowed), err)
+}
+
+func TestConfig_Invalid(t *testing.T) {
+	testCases := []struct {
+		name     string
+		verifier map[string]interface{}
+	}{
+		{name: "Missing selector", verifier: map[string]interface{}{
+			"verification_key_file": "../../example_verification_key.json",
+		}},
+		{name: "Missing key file", verifier: map[string]interface{}{
+			"verification_key_file": "testdata/missing.json",
+			"proof_selector":        "23073",
+		}},
+		{name: "Unknown citizenship", verifier: map[string]interface{}{
+			"verification_key_file": "../../example_verification_key.json",
+			"proof_selector":        "23073",
+			"citizenships":          []interface{}{"XYZ"},
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := newConfig(mapGetter(map[string]map[string]interface{}{
+				"verifier":      tc.verifier,
+				"root_verifier": {"disabled": true},
+			}))
+			assert.Panics(t, func() { cfg.Verifier() })
+		})
+	}
+}
+
+func TestConfig_KeyReloadInterval(t *testing.T) {
+	cfg := newConfig(mapGetter(map[string]map[string]interface{}{
+		"verifier": {},
+	}))
+	assert.Zero(t, cfg.KeyReloadInterval())
+}
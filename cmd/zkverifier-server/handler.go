@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+
+	val "github.com/go-ozzo/ozzo-validation/v4"
+	zkptypes "github.com/iden3/go-rapidsnark/types"
+	kit "github.com/rarimo/zkverifier-kit"
+	"github.com/rarimo/zkverifier-kit/identity"
+	"gitlab.com/distributed_lab/logan/v3"
+)
+
+// maxBodySize is far above the size of a proof with a few dozens of signals
+const maxBodySize = 1 << 20
+
+type proofVerifier interface {
+	VerifyProofContext(context.Context, zkptypes.ZKProof, ...kit.VerifyOption) error
+	ParsePubSignals([]string) (kit.PassportClaims, error)
+}
+
+type handler struct {
+	verifier     proofVerifier
+	rootVerifier identity.RootVerifier
+	log          *logan.Entry
+}
+
+type verifyResponse struct {
+	Valid  bool                `json:"valid"`
+	Claims *kit.PassportClaims `json:"claims,omitempty"`
+	Errors []checkError        `json:"errors,omitempty"`
+}
+
+// checkError is a single failed check, the VerificationError fields are
+// absent for unstructured errors
+type checkError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+	*kit.VerificationError
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// newHandler serves the verification API:
+//   - POST /v1/verify takes ZKProof JSON and responds with decoded claims on
+//     success or with the failed checks
+//   - GET /health responds with 200 while the service is running
+//   - GET /ready responds with 200 when the identity root verifier is reachable
+//   - GET /metrics exposes Prometheus metrics of verifications
+func newHandler(verifier proofVerifier, rootVerifier identity.RootVerifier, metrics http.Handler, log *logan.Entry) http.Handler {
+	h := handler{
+		verifier:     verifier,
+		rootVerifier: rootVerifier,
+		log:          log,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/verify", h.verify)
+	mux.HandleFunc("GET /health", h.health)
+	mux.HandleFunc("GET /ready", h.ready)
+	mux.Handle("GET /metrics", metrics)
+
+	return mux
+}
+
+func (h handler) verify(w http.ResponseWriter, r *http.Request) {
+	var proof zkptypes.ZKProof
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&proof); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid proof JSON: " + err.Error()})
+		return
+	}
+
+	err := h.verifier.VerifyProofContext(r.Context(), proof)
+	if err == nil {
+		claims, err := h.verifier.ParsePubSignals(proof.PubSignals)
+		if err != nil {
+			h.log.WithError(err).Error("failed to parse signals of valid proof")
+			writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "internal error"})
+			return
+		}
+
+		writeJSON(w, http.StatusOK, verifyResponse{Valid: true, Claims: &claims})
+		return
+	}
+
+	var errs val.Errors
+	if !errors.As(err, &errs) {
+		h.log.WithError(err).Error("failed to verify proof")
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: "internal error"})
+		return
+	}
+
+	writeJSON(w, http.StatusUnprocessableEntity, verifyResponse{Errors: toCheckErrors(errs)})
+}
+
+func (h handler) health(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, struct{}{})
+}
+
+func (h handler) ready(w http.ResponseWriter, r *http.Request) {
+	// any root is suitable to check the contract availability
+	err := h.rootVerifier.VerifyRootContext(r.Context(), "0")
+	if errors.Is(err, identity.ErrContractCall) {
+		h.log.WithError(err).Warn("identity root verifier is not ready")
+		writeJSON(w, http.StatusServiceUnavailable, errorResponse{Error: "identity root verifier is unavailable"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, struct{}{})
+}
+
+func toCheckErrors(errs val.Errors) []checkError {
+	fields := make([]string, 0, len(errs))
+	for field := range errs {
+		fields = append(fields, field)
+	}
+	slices.Sort(fields)
+
+	res := make([]checkError, len(fields))
+	for i, field := range fields {
+		res[i] = checkError{Field: field, Message: errs[field].Error()}
+		errors.As(errs[field], &res[i].VerificationError)
+	}
+
+	return res
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	zkptypes "github.com/iden3/go-rapidsnark/types"
+	kit "github.com/rarimo/zkverifier-kit"
+	"github.com/rarimo/zkverifier-kit/identity"
+	"github.com/rarimo/zkverifier-kit/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/distributed_lab/logan/v3"
+)
+
+// storedRoot is IdStateRoot of testdata/proof.json in hex
+const storedRoot = "1fd232b83b1927f2a8ede62ffe15c31d18782dd513e08f4aabeaf2e8e4c32417"
+
+// stubVerifier returns err from VerifyProofContext and decodes the signals
+// with the embedded verifier
+type stubVerifier struct {
+	*kit.Verifier
+	err error
+}
+
+func (v stubVerifier) VerifyProofContext(context.Context, zkptypes.ZKProof, ...kit.VerifyOption) error {
+	return v.err
+}
+
+type failingCaller struct{}
+
+func (failingCaller) IsRootValid(*bind.CallOpts, [32]byte) (bool, error) {
+	return false, errors.New("connection refused")
+}
+
+func TestHandler_Verify(t *testing.T) {
+	proof, err := os.ReadFile("testdata/proof.json")
+	require.NoError(t, err)
+
+	rv := identity.NewVerifier(new(testutil.MockCaller).WithRoot(storedRoot), time.Second)
+	policy, err := kit.NewPassportVerifier(nil,
+		kit.WithVerificationKeyFile("../../example_verification_key.json"),
+		kit.WithProofSelectorValue("23073"),
+		kit.WithCitizenships("USA"),
+		kit.WithIdentityVerifier(rv),
+		// the proof was generated on this date
+		kit.WithNow(time.Date(2024, 5, 24, 12, 0, 0, 0, time.UTC)),
+	)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		verifier   proofVerifier
+		body       string
+		wantStatus int
+		check      func(t *testing.T, body []byte)
+	}{
+		{
+			name:       "Valid proof",
+			verifier:   stubVerifier{Verifier: policy},
+			body:       string(proof),
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var resp verifyResponse
+				require.NoError(t, json.Unmarshal(body, &resp))
+				assert.True(t, resp.Valid)
+				require.NotNil(t, resp.Claims)
+				require.NotNil(t, resp.Claims.Citizenship)
+				assert.Equal(t, "UKR", *resp.Claims.Citizenship)
+			},
+		},
+		{
+			name:       "Policy violation",
+			verifier:   policy,
+			body:       string(proof),
+			wantStatus: http.StatusUnprocessableEntity,
+			check: func(t *testing.T, body []byte) {
+				var resp struct {
+					Valid  bool `json:"valid"`
+					Errors []struct {
+						Field   string `json:"field"`
+						Code    string `json:"code"`
+						Signal  string `json:"signal"`
+						Message string `json:"message"`
+						Actual  any    `json:"actual"`
+					} `json:"errors"`
+				}
+				require.NoError(t, json.Unmarshal(body, &resp))
+				assert.False(t, resp.Valid)
+				require.Len(t, resp.Errors, 1)
+				assert.Equal(t, "pub_signals/citizenship", resp.Errors[0].Field)
+				assert.Equal(t, string(kit.CodeCitizenshipNotAllowed), resp.Errors[0].Code)
+				assert.Equal(t, "citizenship", resp.Errors[0].Signal)
+				assert.Equal(t, "UKR", resp.Errors[0].Actual)
+			},
+		},
+		{
+			name:       "Malformed JSON",
+			verifier:   policy,
+			body:       `{"proof":`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "Internal error",
+			verifier:   stubVerifier{Verifier: policy, err: identity.ErrContractCall},
+			body:       string(proof),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(newHandler(tc.verifier, rv, http.NotFoundHandler(), logan.New()))
+			defer srv.Close()
+
+			resp, err := http.Post(srv.URL+"/v1/verify", "application/json", strings.NewReader(tc.body))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tc.wantStatus, resp.StatusCode)
+			if tc.check != nil {
+				var body json.RawMessage
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+				tc.check(t, body)
+			}
+		})
+	}
+}
+
+func TestHandler_Probes(t *testing.T) {
+	testCases := []struct {
+		name       string
+		rv         identity.RootVerifier
+		path       string
+		wantStatus int
+	}{
+		{name: "Health", rv: identity.NewVerifier(failingCaller{}, time.Second), path: "/health", wantStatus: http.StatusOK},
+		{name: "Ready", rv: identity.NewVerifier(new(testutil.MockCaller).WithRoot(storedRoot), time.Second), path: "/ready", wantStatus: http.StatusOK},
+		{name: "Ready when disabled", rv: identity.NewDisabledVerifier(), path: "/ready", wantStatus: http.StatusOK},
+		{name: "Not ready", rv: identity.NewVerifier(failingCaller{}, time.Second), path: "/ready", wantStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			newHandler(nil, tc.rv, http.NotFoundHandler(), logan.New()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			assert.Equal(t, tc.wantStatus, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// Command zkverifier-server is an HTTP service, which verifies passport proofs
+// against the policy from config. The config file is provided in KV_VIPER_FILE
+// environment variable, see config for its format.
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3"
+)
+
+const shutdownTimeout = 10 * time.Second
+
+func main() {
+	log := logan.New()
+	cfg := newConfig(kv.MustFromEnv())
+
+	verifier := cfg.Verifier()
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(cfg.Metrics())
+
+	srv := &http.Server{
+		Handler:           newHandler(verifier, cfg.ProvideVerifier(), promhttp.HandlerFor(registry, promhttp.HandlerOpts{}), log),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if interval := cfg.KeyReloadInterval(); interval > 0 {
+		go func() {
+			err := verifier.WatchVerificationKey(ctx, interval, func(err error) {
+				if err != nil {
+					log.WithError(err).Error("failed to reload verification key")
+					return
+				}
+				log.Info("verification key reloaded")
+			})
+			if err != nil {
+				log.WithError(err).Error("failed to watch verification key")
+			}
+		}()
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.WithError(err).Error("failed to shutdown server")
+		}
+	}()
+
+	listener := cfg.Listener()
+	log.WithField("addr", listener.Addr().String()).Info("starting server")
+
+	if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.WithError(err).Fatal("server failed")
+	}
+}
@@ -0,0 +1,243 @@
+// Command zkverify inspects and verifies a passport proof from file. It prints
+// the decoded public signals and the result of every check.
+//
+// Usage:
+//
+//	zkverify -key verification_key.json [flags] proof.json
+//
+// Exit codes: 0 if the proof is valid, 1 if verification failed, 2 on invalid
+// input, 3 on internal error.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	zkptypes "github.com/iden3/go-rapidsnark/types"
+	kit "github.com/rarimo/zkverifier-kit"
+)
+
+const (
+	exitValid = iota
+	exitInvalid
+	exitUsage
+	exitInternal
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("zkverify", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	var (
+		keyFile     = flags.String("key", "", "verification key file (required)")
+		age         = flags.Int("age", -1, "minimal age of the passport holder")
+		citizenship = flags.String("citizenship", "", "comma-separated allowed Alpha-3 citizenship codes")
+		eventID     = flags.String("event-id", "", "expected event ID as a decimal integer")
+		selector    = flags.String("selector", "", "expected selector as a decimal integer, the check is skipped if not set")
+		now         = flags.String("now", "", "date of verification in YYYY-MM-DD or RFC 3339 format, defaults to the current time")
+	)
+
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: zkverify -key verification_key.json [flags] proof.json")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *keyFile == "" || flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	proof, err := readProof(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	verifiedAt := time.Now()
+	if *now != "" {
+		if verifiedAt, err = parseNow(*now); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+	}
+
+	printSignals(stdout, proof.PubSignals, verifiedAt)
+
+	opts := []kit.VerifyOption{
+		kit.WithVerificationKeyFile(*keyFile),
+		kit.WithProofSelectorValue(*selector),
+		kit.WithAgeAbove(*age),
+		kit.WithEventID(*eventID),
+		kit.WithNow(verifiedAt),
+	}
+	if *citizenship != "" {
+		opts = append(opts, kit.WithCitizenships(strings.Split(*citizenship, ",")...))
+	}
+
+	verifier, err := kit.NewPassportVerifier(nil, opts...)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	report := verifier.Explain(proof)
+	printReport(stdout, report)
+
+	if c, ok := internalFailure(report); ok {
+		fmt.Fprintf(stderr, "failed to verify proof: %s: %s\n", c.Name, c.Message)
+		return exitInternal
+	}
+	if !report.Valid {
+		fmt.Fprintln(stdout, "\nProof is INVALID")
+		return exitInvalid
+	}
+
+	fmt.Fprintln(stdout, "\nProof is valid")
+	return exitValid
+}
+
+func readProof(name string) (zkptypes.ZKProof, error) {
+	var proof zkptypes.ZKProof
+
+	raw, err := os.ReadFile(name)
+	if err != nil {
+		return proof, fmt.Errorf("failed to read proof: %w", err)
+	}
+	if err = json.Unmarshal(raw, &proof); err != nil {
+		return proof, fmt.Errorf("failed to unmarshal proof: %w", err)
+	}
+
+	return proof, nil
+}
+
+func parseNow(s string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return t, fmt.Errorf("invalid --now value %q: expected YYYY-MM-DD or RFC 3339", s)
+	}
+
+	return t, nil
+}
+
+// printSignals prints every signal with its name in the default layout and the
+// decoded value, when the signals can be decoded. The dates are decoded
+// relative to now.
+func printSignals(w io.Writer, signals []string, now time.Time) {
+	decoded := make(map[kit.PubSignal]string)
+	if claims, err := kit.ParsePubSignalsAt(signals, now); err == nil {
+		decoded = decodedSignals(claims)
+	} else {
+		fmt.Fprintf(w, "Failed to decode signals: %s\n", err)
+	}
+
+	names := make(map[int]kit.PubSignal)
+	for s := range kit.DefaultSignalLayout().Signals {
+		names[int(s)] = s
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tSIGNAL\tRAW\tDECODED")
+	for i, raw := range signals {
+		name, value := "-", ""
+		if s, ok := names[i]; ok {
+			name, value = s.String(), decoded[s]
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", i, name, raw, value)
+	}
+	_ = tw.Flush()
+	fmt.Fprintln(w)
+}
+
+func decodedSignals(c kit.PassportClaims) map[kit.PubSignal]string {
+	res := map[kit.PubSignal]string{
+		kit.Selector: c.Selector.String(),
+	}
+
+	if c.Citizenship != nil {
+		res[kit.Citizenship] = *c.Citizenship
+	}
+
+	for s, date := range map[kit.PubSignal]*time.Time{
+		kit.BirthDate:                c.BirthDate,
+		kit.BirthdateLowerBound:      c.BirthDateLowerBound,
+		kit.BirthdateUpperBound:      c.BirthDateUpperBound,
+		kit.ExpirationDate:           c.ExpirationDate,
+		kit.ExpirationDateLowerBound: c.ExpirationDateLowerBound,
+	} {
+		if date != nil {
+			res[s] = date.Format(time.DateOnly)
+		}
+	}
+
+	if c.TimestampUpperBound != nil {
+		res[kit.TimestampUpperBound] = time.Unix(*c.TimestampUpperBound, 0).UTC().Format(time.RFC3339)
+	}
+	if c.IdentityCounterUpperBound != nil {
+		res[kit.IdentityCounterUpperBound] = fmt.Sprint(*c.IdentityCounterUpperBound)
+	}
+
+	return res
+}
+
+// internalFailure returns the failed check without a code, e.g. on a failed
+// call of the identity root verifier, which is not a verification failure
+func internalFailure(r kit.Report) (kit.Check, bool) {
+	for _, c := range r.Checks {
+		if c.Status == kit.CheckFailed && c.Code == "" {
+			return c, true
+		}
+	}
+	return kit.Check{}, false
+}
+
+// printReport prints the status of each check of the report, the failure code
+// and message, and the expected and actual values
+func printReport(w io.Writer, r kit.Report) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tSTATUS\tDETAILS")
+	for _, c := range r.Checks {
+		details := c.Message
+		if c.Code != "" {
+			details = fmt.Sprintf("%s: %s", c.Code, details)
+		}
+		if c.Status == kit.CheckFailed {
+			for _, v := range []struct {
+				name  string
+				value any
+			}{{"expected", c.Expected}, {"actual", c.Actual}} {
+				if v.value != nil {
+					details += fmt.Sprintf("; %s %s", v.name, formatValue(v.value))
+				}
+			}
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", c.Name, c.Status, details)
+	}
+	_ = tw.Flush()
+}
+
+// formatValue prints the value as JSON, e.g. dates and lists
+func formatValue(v any) string {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(raw)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+	"time"
+
+	kit "github.com/rarimo/zkverifier-kit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	const (
+		key   = "../../example_verification_key.json"
+		proof = "testdata/proof.json"
+		// groth16 verification always fails with this key
+		invalidKey = "testdata/invalid_key.json"
+	)
+
+	testCases := []struct {
+		name     string
+		args     []string
+		wantCode int
+		wantOut  []string
+	}{
+		{
+			name:     "Citizenship mismatch",
+			args:     []string{"-key", key, "--now", "2024-05-24", "--citizenship", "USA", proof},
+			wantCode: exitInvalid,
+			wantOut: []string{
+				`citizenship +5589842 +UKR`,
+				`birth_date_upper_bound +53009295159860 +2006-05-24`,
+				`citizenship +failed +citizenship_not_allowed: must be a valid value; expected {"allowed":\["USA"\]}; actual "UKR"`,
+				`birth_date +skipped +age and birth date options are not set`,
+				`expiration_date_lower_bound +passed`,
+				`identities +skipped`,
+				`groth16 +failed +groth16_failed`,
+			},
+		},
+		{
+			name:     "Age check passes",
+			args:     []string{"-key", invalidKey, "--now", "2024-05-24T10:00:00Z", "--age", "18", proof},
+			wantCode: exitInvalid,
+			wantOut:  []string{`birth_date +passed`, `groth16 +failed`},
+		},
+		{
+			name:     "Selector mismatch",
+			args:     []string{"-key", key, "--selector", "1", proof},
+			wantCode: exitInvalid,
+			wantOut:  []string{`selector +failed +selector_mismatch`, `nullifier +passed`},
+		},
+		{
+			name:     "Missing key",
+			args:     []string{proof},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "Selector is skipped without flag",
+			args:     []string{"-key", invalidKey, "--now", "2024-05-24", proof},
+			wantCode: exitInvalid,
+			wantOut:  []string{`selector +skipped +WithProofSelectorValue is not set`, `groth16 +failed`},
+		},
+		{
+			name:     "Signals are decoded at the given date",
+			args:     []string{"-key", invalidKey, "--now", "2090-01-01", proof},
+			wantCode: exitInvalid,
+			wantOut:  []string{`expiration_date_lower_bound +55199728480820 +2124-05-24`},
+		},
+		{
+			name:     "Invalid date",
+			args:     []string{"-key", key, "--now", "24.05.2024", proof},
+			wantCode: exitUsage,
+		},
+		{
+			name:     "Missing proof file",
+			args:     []string{"-key", key, "nonexistent.json"},
+			wantCode: exitUsage,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tc.args, &stdout, &stderr)
+			assert.Equal(t, tc.wantCode, code, stderr.String())
+
+			for _, want := range tc.wantOut {
+				assert.Regexp(t, regexp.MustCompile(want), stdout.String())
+			}
+		})
+	}
+}
+
+func TestDecodedSignals(t *testing.T) {
+	lower := time.Date(1998, time.May, 25, 0, 0, 0, 0, time.UTC)
+	upper := time.Date(2006, time.May, 24, 0, 0, 0, 0, time.UTC)
+
+	decoded := decodedSignals(kit.PassportClaims{
+		BirthDateLowerBound: &lower,
+		BirthDateUpperBound: &upper,
+	})
+	assert.Equal(t, "1998-05-25", decoded[kit.BirthdateLowerBound])
+	assert.Equal(t, "2006-05-24", decoded[kit.BirthdateUpperBound])
+	assert.NotContains(t, decoded, kit.BirthDate)
+}
@@ -0,0 +1,176 @@
+package zkverifier_kit
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrUnknownCountry      = errors.New("unknown country code")
+	ErrInvalidCountryGroup = errors.New("invalid country group name")
+)
+
+// Country is an entry of ISO 3166-1 table. The codes, which are used in
+// passports instead of ISO 3166 ones, are described in ICAO Doc 9303. Those
+// without a country have only Alpha3 code, e.g. "XXA" for stateless persons.
+type Country struct {
+	Alpha2  string `json:"alpha2,omitempty"`
+	Alpha3  string `json:"alpha3"`
+	Numeric string `json:"numeric,omitempty"`
+	Name    string `json:"name"`
+}
+
+// icaoCodes are the passport nationality codes of ICAO Doc 9303, which are not
+// in ISO 3166-1
+var icaoCodes = []Country{
+	{Alpha3: "EUE", Name: "European Union"},
+	{Alpha3: "RKS", Name: "Kosovo"},
+	{Alpha3: "UNA", Name: "United Nations specialized agency"},
+	{Alpha3: "UNK", Name: "Kosovo (UNMIK)"},
+	{Alpha3: "UNO", Name: "United Nations"},
+	{Alpha3: "XOM", Name: "Sovereign Military Order of Malta"},
+	{Alpha3: "XPO", Name: "Interpol"},
+	{Alpha3: "XXA", Name: "Stateless person"},
+	{Alpha3: "XXB", Name: "Refugee (1951 Convention)"},
+	{Alpha3: "XXC", Name: "Refugee (other)"},
+	{Alpha3: "XXX", Name: "Unspecified nationality"},
+}
+
+// icaoAliases map the passport nationality codes to the canonical Alpha-3 ones
+var icaoAliases = map[string]string{
+	"D":   "DEU",
+	"GBD": "GBR", // British Overseas Territories citizen
+	"GBN": "GBR", // British National (Overseas)
+	"GBO": "GBR", // British Overseas citizen
+	"GBP": "GBR", // British protected person
+	"GBS": "GBR", // British subject
+}
+
+// countryIndex maps all the codes, including aliases, to the table entries
+var countryIndex = func() map[string]Country {
+	index := make(map[string]Country, 3*len(iso3166)+len(icaoCodes)+len(icaoAliases))
+	for _, c := range append(slices.Clone(iso3166), icaoCodes...) {
+		index[c.Alpha3] = c
+		if c.Alpha2 != "" {
+			index[c.Alpha2] = c
+			index[c.Numeric] = c
+		}
+	}
+	for alias, code := range icaoAliases {
+		index[alias] = index[code]
+	}
+	return index
+}()
+
+// LookupCountry finds the country by ISO 3166 Alpha-2, Alpha-3 or numeric code,
+// or by the passport nationality code of ICAO Doc 9303, e.g. "D" for Germany.
+// The lookup is case-insensitive, MRZ fillers '<' are ignored.
+func LookupCountry(code string) (Country, bool) {
+	code = strings.ToUpper(strings.TrimRight(strings.TrimSpace(code), "<"))
+	if len(code) > 0 && len(code) < 3 && strings.Trim(code, "0123456789") == "" {
+		code = strings.Repeat("0", 3-len(code)) + code
+	}
+
+	c, ok := countryIndex[code]
+	return c, ok
+}
+
+// canonicalCountry returns Alpha-3 code of the country, or the code itself
+// if it is unknown
+func canonicalCountry(code string) string {
+	if c, ok := LookupCountry(code); ok {
+		return c.Alpha3
+	}
+	return code
+}
+
+var (
+	countryGroupsMu sync.RWMutex
+	// countryGroups maps upper-case group names to Alpha-3 country codes
+	countryGroups = map[string][]string{
+		"EU": euCountries,
+		"EEA": append(slices.Clone(euCountries),
+			"ISL", "LIE", "NOR",
+		),
+	}
+)
+
+var euCountries = []string{
+	"AUT", "BEL", "BGR", "HRV", "CYP", "CZE", "DNK", "EST", "FIN",
+	"FRA", "DEU", "GRC", "HUN", "IRL", "ITA", "LVA", "LTU", "LUX",
+	"MLT", "NLD", "POL", "PRT", "ROU", "SVK", "SVN", "ESP", "SWE",
+}
+
+// RegisterCountryGroup adds the named group of Alpha-3 country codes, which can
+// be used in WithCitizenships and WithCitizenshipsExcluded instead of listing
+// the codes. Registering a built-in group ("EU", "EEA") overrides it. The names
+// are case-insensitive. The groups are expanded when the options are applied,
+// so the verifiers that already exist are not affected. The name must not be
+// empty or resolve to a country with LookupCountry, otherwise
+// ErrInvalidCountryGroup is returned.
+func RegisterCountryGroup(name string, codes ...string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("%w: empty name", ErrInvalidCountryGroup)
+	}
+	if c, ok := LookupCountry(name); ok {
+		return fmt.Errorf("%w: %q is the code of %s", ErrInvalidCountryGroup, name, c.Name)
+	}
+
+	countryGroupsMu.Lock()
+	defer countryGroupsMu.Unlock()
+	countryGroups[strings.ToUpper(name)] = slices.Clone(codes)
+	return nil
+}
+
+// CountryGroup returns the codes of the registered group
+func CountryGroup(name string) ([]string, bool) {
+	countryGroupsMu.RLock()
+	defer countryGroupsMu.RUnlock()
+
+	codes, ok := countryGroups[strings.ToUpper(name)]
+	return slices.Clone(codes), ok
+}
+
+// expandCountries replaces group names with their codes, normalizes the codes
+// to Alpha-3 and removes duplicates. Country codes take precedence over group
+// names.
+func expandCountries(codes []string) ([]interface{}, error) {
+	var (
+		expanded = make([]interface{}, 0, len(codes))
+		seen     = make(map[string]bool, len(codes))
+		errs     []error
+	)
+
+	add := func(code string) {
+		c, ok := LookupCountry(code)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%w: %q", ErrUnknownCountry, code))
+			return
+		}
+		if !seen[c.Alpha3] {
+			seen[c.Alpha3] = true
+			expanded = append(expanded, c.Alpha3)
+		}
+	}
+
+	for _, code := range codes {
+		if _, ok := LookupCountry(code); ok {
+			add(code)
+			continue
+		}
+
+		group, ok := CountryGroup(code)
+		if !ok {
+			add(code)
+			continue
+		}
+		for _, c := range group {
+			add(c)
+		}
+	}
+
+	return expanded, errors.Join(errs...)
+}
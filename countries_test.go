@@ -0,0 +1,147 @@
+package zkverifier_kit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rarimo/zkverifier-kit/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountryGroups(t *testing.T) {
+	eu, ok := CountryGroup("eu")
+	require.True(t, ok)
+	assert.Len(t, eu, 27)
+
+	eea, ok := CountryGroup("EEA")
+	require.True(t, ok)
+	assert.Len(t, eea, 30)
+	assert.Subset(t, eea, eu)
+
+	codes, err := expandCountries([]string{"UKR", "USA", "UA", "GBR"})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"UKR", "USA", "GBR"}, codes)
+
+	require.NoError(t, RegisterCountryGroup("Eastern_Partnership", "ARM", "AZE", "BLR", "GEO", "MDA", "UKR"))
+	t.Cleanup(func() {
+		countryGroupsMu.Lock()
+		defer countryGroupsMu.Unlock()
+		delete(countryGroups, "EASTERN_PARTNERSHIP")
+	})
+	codes, err = expandCountries([]string{"EASTERN_PARTNERSHIP", "POL"})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"ARM", "AZE", "BLR", "GEO", "MDA", "UKR", "POL"}, codes)
+
+	_, err = expandCountries([]string{"UKR", "ENG", "XYZ"})
+	assert.ErrorIs(t, err, ErrUnknownCountry)
+	assert.ErrorContains(t, err, `"ENG"`)
+	assert.ErrorContains(t, err, `"XYZ"`)
+}
+
+func TestRegisterCountryGroup_Invalid(t *testing.T) {
+	for _, name := range []string{"UKR", "ua", "804", "D", "XXA", " "} {
+		err := RegisterCountryGroup(name, "POL")
+		assert.ErrorIs(t, err, ErrInvalidCountryGroup, name)
+
+		_, ok := CountryGroup(name)
+		assert.False(t, ok, name)
+	}
+
+	// the code is not shadowed even if the group got registered directly
+	countryGroupsMu.Lock()
+	countryGroups["UA"] = []string{"POL"}
+	countryGroupsMu.Unlock()
+	t.Cleanup(func() {
+		countryGroupsMu.Lock()
+		defer countryGroupsMu.Unlock()
+		delete(countryGroups, "UA")
+	})
+
+	codes, err := expandCountries([]string{"UA"})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"UKR"}, codes)
+}
+
+func TestLookupCountry(t *testing.T) {
+	testCases := []struct {
+		code string
+		want string
+	}{
+		{code: "UKR", want: "UKR"},
+		{code: "ua", want: "UKR"},
+		{code: "804", want: "UKR"},
+		{code: "40", want: "AUT"},
+		{code: "D<<", want: "DEU"},
+		{code: "GBN", want: "GBR"},
+		{code: "XXA", want: "XXA"},
+		{code: " usa ", want: "USA"},
+		{code: "ENG"},
+		{code: "000"},
+		{code: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.code, func(t *testing.T) {
+			c, ok := LookupCountry(tc.code)
+			if tc.want == "" {
+				assert.False(t, ok)
+				return
+			}
+
+			require.True(t, ok)
+			assert.Equal(t, tc.want, c.Alpha3)
+			assert.NotEmpty(t, c.Name)
+		})
+	}
+}
+
+func TestVerifyProof_Citizenship(t *testing.T) {
+	key, proof := testutil.Groth16Proof(validProof.PubSignals)
+
+	testCases := []struct {
+		name string
+		opts []VerifyOption
+		want Code
+	}{
+		{name: "Allowed", opts: []VerifyOption{WithCitizenships("USA", ukrCitizenship)}},
+		{name: "Allowed alpha-2", opts: []VerifyOption{WithCitizenships("us", "UA")}},
+		{name: "Excluded numeric", opts: []VerifyOption{WithCitizenshipsExcluded("804")}, want: CodeCitizenshipExcluded},
+		{name: "Not allowed group", opts: []VerifyOption{WithCitizenships("EU")}, want: CodeCitizenshipNotAllowed},
+		{name: "Not excluded", opts: []VerifyOption{WithCitizenshipsExcluded("EEA", "RUS", "BLR")}},
+		{name: "Excluded", opts: []VerifyOption{WithCitizenshipsExcluded("RUS", ukrCitizenship)}, want: CodeCitizenshipExcluded},
+		{
+			name: "Allowed and excluded",
+			opts: []VerifyOption{WithCitizenships("EU", ukrCitizenship), WithCitizenshipsExcluded(ukrCitizenship)},
+			want: CodeCitizenshipExcluded,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := append([]VerifyOption{WithNow(proofDate), WithProofSelectorValue(validSelector)}, tc.opts...)
+			verifier, err := NewPassportVerifier(key, opts...)
+			require.NoError(t, err)
+
+			err = verifier.VerifyProof(proof)
+			if tc.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, tc.want), err)
+		})
+	}
+}
+
+func TestNewPassportVerifier_UnknownCountry(t *testing.T) {
+	_, err := NewPassportVerifier(verificationKey, WithCitizenships("USA", "ENG"))
+	assert.ErrorIs(t, err, ErrUnknownCountry)
+
+	_, err = NewPassportVerifier(verificationKey, WithCitizenshipsExcluded("XYZ"))
+	assert.ErrorIs(t, err, ErrUnknownCountry)
+
+	// the codes of the verifier can be replaced on verification
+	verifier, err := NewPassportVerifier(verificationKey, WithCitizenships("UKR"))
+	require.NoError(t, err)
+	assert.ErrorIs(t, verifier.VerifyProof(validProof, WithCitizenships("ENG")), ErrUnknownCountry)
+}
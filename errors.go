@@ -0,0 +1,180 @@
+package zkverifier_kit
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	val "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Code is a stable identifier of the verification failure reason. It
+// implements error, so that it can be matched with errors.Is:
+//
+//	if errors.Is(err, kit.CodeAgeTooLow) { ... }
+type Code string
+
+const (
+	CodeMalformedProof          Code = "malformed_proof"
+	CodeSelectorMismatch        Code = "selector_mismatch"
+	CodeFieldNotSelected        Code = "field_not_selected"
+	CodeNullifierMissing        Code = "nullifier_missing"
+	CodeNullifierUsed           Code = "nullifier_used"
+	CodeRootInvalid             Code = "root_invalid"
+	CodeEventIDMismatch         Code = "event_id_mismatch"
+	CodeEventDataMismatch       Code = "event_data_mismatch"
+	CodeCitizenshipNotAllowed   Code = "citizenship_not_allowed"
+	CodeCitizenshipExcluded     Code = "citizenship_excluded"
+	CodeAgeTooLow               Code = "age_too_low"
+	CodeAgeTooHigh              Code = "age_too_high"
+	CodePassportExpired         Code = "passport_expired"
+	CodeExpirationBoundMismatch Code = "expiration_bound_mismatch"
+	CodeIdentityCounterExceeded Code = "identity_counter_exceeded"
+	CodeIdentityTooOld          Code = "identity_too_old"
+	CodeGroth16Failed           Code = "groth16_failed"
+)
+
+func (c Code) Error() string {
+	return string(c)
+}
+
+// NoSignal is set in VerificationError when the failure is not related to a
+// specific public signal, e.g. on Groth16 verification
+const NoSignal PubSignal = -1
+
+// VerificationError describes a single failed check. Its message is the same as
+// the one of the underlying validation error.
+type VerificationError struct {
+	Code     Code      `json:"code"`
+	Signal   PubSignal `json:"signal"`
+	Expected any       `json:"expected,omitempty"`
+	Actual   any       `json:"actual,omitempty"`
+	Err      error     `json:"-"`
+}
+
+func (e *VerificationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *VerificationError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the target is the error Code
+func (e *VerificationError) Is(target error) bool {
+	code, ok := target.(Code)
+	return ok && code == e.Code
+}
+
+// Errors is returned from Verifier.VerifyProof on verification failure. The
+// keys are the checked fields, e.g. "pub_signals/citizenship", and the values
+// are mostly *VerificationError. It renders and marshals the same as
+// val.Errors, which it can be converted to with errors.As, while errors.Is and
+// errors.As look through every value.
+type Errors val.Errors
+
+func (e Errors) Error() string {
+	return val.Errors(e).Error()
+}
+
+func (e Errors) MarshalJSON() ([]byte, error) {
+	return val.Errors(e).MarshalJSON()
+}
+
+// Unwrap returns the values sorted by keys
+func (e Errors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, k := range sortedKeys(val.Errors(e)) {
+		if e[k] != nil {
+			errs = append(errs, e[k])
+		}
+	}
+
+	return errs
+}
+
+// As converts the errors to val.Errors for the callers, which expect it
+func (e Errors) As(target any) bool {
+	errs, ok := target.(*val.Errors)
+	if ok {
+		*errs = val.Errors(e)
+	}
+	return ok
+}
+
+// VerificationErrors returns the failed checks of the error, returned from
+// Verifier.VerifyProof, sorted by the checked fields. It is empty for valid
+// proofs and internal errors.
+func VerificationErrors(err error) []*VerificationError {
+	var errs val.Errors
+	if !errors.As(err, &errs) {
+		return nil
+	}
+
+	var verrs []*VerificationError
+	for _, field := range sortedKeys(errs) {
+		var verr *VerificationError
+		if errors.As(errs[field], &verr) {
+			verrs = append(verrs, verr)
+		}
+	}
+
+	return verrs
+}
+
+func sortedKeys(e val.Errors) []string {
+	keys := make([]string, 0, len(e))
+	for k := range e {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// newErrors removes nil values and converts the rest to Errors, returns nil
+// when nothing is left
+func newErrors(all val.Errors) error {
+	if all.Filter() == nil {
+		return nil
+	}
+	return Errors(all)
+}
+
+// fieldNotSelectedError is returned when the option requires the field, which
+// is not enabled in selector
+type fieldNotSelectedError struct {
+	option string
+	field  SelectorField
+	mask   SelectorMask
+}
+
+func (e *fieldNotSelectedError) Error() string {
+	return fmt.Sprintf("option %s requires field %s, but selector does not enable it", e.option, e.field)
+}
+
+// verificationErr wraps non-nil error into VerificationError. The errors of
+// fields that are not enabled in selector get CodeFieldNotSelected regardless
+// of the provided code.
+func verificationErr(err error, code Code, signal PubSignal, expected, actual any) error {
+	if err == nil {
+		return nil
+	}
+
+	var verr *VerificationError
+	if errors.As(err, &verr) {
+		return err
+	}
+
+	var fe *fieldNotSelectedError
+	if errors.As(err, &fe) {
+		code, expected, actual = CodeFieldNotSelected, fe.field.String(), fe.mask.String()
+	}
+
+	return &VerificationError{
+		Code:     code,
+		Signal:   signal,
+		Expected: expected,
+		Actual:   actual,
+		Err:      err,
+	}
+}
@@ -0,0 +1,165 @@
+package zkverifier_kit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	val "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/rarimo/zkverifier-kit/identity"
+	"github.com/rarimo/zkverifier-kit/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyProof_Errors(t *testing.T) {
+	badVerifier := identity.NewVerifier(new(testutil.MockCaller).WithRoot("ffffff"), 0)
+	// groth16 verification always fails with this key
+	invalidKey := mismatchedKey(t, 1)
+
+	testCases := []struct {
+		name   string
+		opts   []VerifyOption
+		proof  func([]string)
+		code   Code
+		signal PubSignal
+	}{
+		{
+			name:   "Citizenship not allowed",
+			opts:   []VerifyOption{WithCitizenships(usaCitizenship)},
+			code:   CodeCitizenshipNotAllowed,
+			signal: Citizenship,
+		},
+		{
+			name:   "Age too low",
+			opts:   []VerifyOption{WithAgeAbove(higherAge)},
+			code:   CodeAgeTooLow,
+			signal: BirthdateUpperBound,
+		},
+		{
+			name:   "Root invalid",
+			opts:   []VerifyOption{WithIdentityVerifier(badVerifier)},
+			code:   CodeRootInvalid,
+			signal: IdStateRoot,
+		},
+		{
+			name:   "Event ID mismatch",
+			opts:   []VerifyOption{WithEventID(invalidEventID)},
+			code:   CodeEventIDMismatch,
+			signal: EventID,
+		},
+		{
+			name:   "Field not selected",
+			opts:   []VerifyOption{WithProofSelectorValue("23041"), WithCitizenships(ukrCitizenship)},
+			proof:  func(s []string) { s[Selector] = "23041" },
+			code:   CodeFieldNotSelected,
+			signal: Citizenship,
+		},
+		{
+			name:   "Malformed proof",
+			proof:  func(s []string) { s[IdentityCounterUpperBound] = "abc" },
+			opts:   []VerifyOption{WithIdentitiesCounter(1)},
+			code:   CodeMalformedProof,
+			signal: IdentityCounterUpperBound,
+		},
+		{
+			name:   "Groth16 failed",
+			code:   CodeGroth16Failed,
+			signal: NoSignal,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			proof := validProof
+			proof.PubSignals = slices.Clone(validProof.PubSignals)
+			if tc.proof != nil {
+				tc.proof(proof.PubSignals)
+			}
+
+			opts := append([]VerifyOption{WithNow(proofDate), WithProofSelectorValue(validSelector)}, tc.opts...)
+			verifier, err := NewPassportVerifier(invalidKey, opts...)
+			require.NoError(t, err)
+
+			err = verifier.VerifyProof(proof)
+			assert.True(t, errors.Is(err, tc.code), err)
+			require.IsType(t, Errors{}, err)
+
+			var verr *VerificationError
+			require.ErrorAs(t, err, &verr)
+
+			// the error can still be handled as val.Errors, as before the
+			// codes were added
+			var errs val.Errors
+			require.ErrorAs(t, err, &errs)
+
+			var found bool
+			for _, e := range errs {
+				require.ErrorAs(t, e, &verr)
+				if verr.Code == tc.code {
+					found = true
+					assert.Equal(t, tc.signal, verr.Signal)
+				}
+			}
+			assert.True(t, found)
+			assert.Contains(t, VerificationErrors(err), errs[field(t, errs, tc.code)])
+		})
+	}
+}
+
+// field returns the key of the failed check with the code
+func field(t *testing.T, errs val.Errors, code Code) string {
+	for k, e := range errs {
+		if errors.Is(e, code) {
+			return k
+		}
+	}
+
+	t.Fatalf("no error with code %s", code)
+	return ""
+}
+
+func TestErrors_Render(t *testing.T) {
+	cause := errors.New("must be a valid value")
+	errs := Errors{
+		"pub_signals/citizenship": verificationErr(cause, CodeCitizenshipNotAllowed, Citizenship, []string{"USA"}, "UKR"),
+		"pub_signals/event_id":    verificationErr(val.ErrInInvalid, CodeEventIDMismatch, EventID, "1", "2"),
+	}
+	old := val.Errors{
+		"pub_signals/citizenship": cause,
+		"pub_signals/event_id":    val.ErrInInvalid,
+	}
+
+	assert.Equal(t, old.Error(), errs.Error())
+	assert.True(t, errors.Is(errs, cause))
+	assert.True(t, errors.Is(errs, CodeEventIDMismatch))
+	assert.False(t, errors.Is(errs, CodeAgeTooLow))
+	assert.True(t, errors.Is(fmt.Errorf("wrapped: %w", errs), CodeEventIDMismatch))
+	assert.False(t, errors.Is(nil, CodeEventIDMismatch))
+
+	var verr *VerificationError
+	require.ErrorAs(t, fmt.Errorf("wrapped: %w", errs), &verr)
+	assert.Equal(t, CodeCitizenshipNotAllowed, verr.Code)
+
+	var asVal val.Errors
+	require.ErrorAs(t, fmt.Errorf("wrapped: %w", errs), &asVal)
+	assert.Equal(t, val.Errors(errs), asVal)
+
+	verrs := VerificationErrors(errs)
+	require.Len(t, verrs, 2)
+	assert.Equal(t, CodeCitizenshipNotAllowed, verrs[0].Code)
+	assert.Equal(t, CodeEventIDMismatch, verrs[1].Code)
+	assert.Empty(t, VerificationErrors(cause))
+
+	raw, err := json.Marshal(errs)
+	require.NoError(t, err)
+	rawOld, err := json.Marshal(old)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(rawOld), string(raw))
+
+	raw, err = json.Marshal(errs["pub_signals/citizenship"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"code":"citizenship_not_allowed","signal":"citizenship","expected":["USA"],"actual":"UKR"}`, string(raw))
+}
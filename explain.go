@@ -0,0 +1,273 @@
+package zkverifier_kit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	zkptypes "github.com/iden3/go-rapidsnark/types"
+	"github.com/rarimo/zkverifier-kit/identity"
+)
+
+// CheckStatus is the outcome of a single check in Report
+type CheckStatus string
+
+const (
+	CheckPassed CheckStatus = "passed"
+	CheckFailed CheckStatus = "failed"
+	// CheckSkipped is set when the option of the check is not set, or the check
+	// can't be done, e.g. on malformed public signals
+	CheckSkipped CheckStatus = "skipped"
+)
+
+// Check is an entry of Report. Expected and Actual are the decoded values when
+// possible, e.g. time.Time for dates and Alpha-3 code for citizenship,
+// otherwise the raw public signals. Code and Message are set on failure,
+// Message also describes the reason of skipping.
+type Check struct {
+	Name     string      `json:"name"`
+	Status   CheckStatus `json:"status"`
+	Code     Code        `json:"code,omitempty"`
+	Message  string      `json:"message,omitempty"`
+	Expected any         `json:"expected,omitempty"`
+	Actual   any         `json:"actual,omitempty"`
+}
+
+// Report is returned from Verifier.Explain, it lists all the checks in the
+// order of verification
+type Report struct {
+	// Valid is true when none of the checks failed, in which case VerifyProof
+	// accepts the proof, unless its nullifier was already used
+	Valid  bool    `json:"valid"`
+	Checks []Check `json:"checks"`
+}
+
+// Check returns the check by name
+func (r Report) Check(name string) (Check, bool) {
+	for _, c := range r.Checks {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Check{}, false
+}
+
+// Explain runs the same checks as VerifyProof, but instead of stopping at the
+// first structural error, it reports the outcome of each one with the expected
+// and the actual values. It is meant for audits and support, so it has no side
+// effects: the nullifier is checked for presence and is never reserved in the
+// store of WithNullifierStore. The options override the initial ones, as in
+// VerifyProof.
+//
+// The checks are named: options, proof, pub_signals, selector, nullifier,
+// id_state_root, event_id, citizenship, event_data, birth_date (the birth date
+// or its bounds), expiration_date_lower_bound, expiration_date, identities
+// (the identity counter or the creation timestamp) and groth16.
+func (v *Verifier) Explain(proof zkptypes.ZKProof, options ...VerifyOption) Report {
+	return v.ExplainContext(context.Background(), proof, options...)
+}
+
+// ExplainContext is the same as Explain, but the context is passed to
+// IdentityRootVerifier, as in VerifyProofContext.
+func (v *Verifier) ExplainContext(ctx context.Context, proof zkptypes.ZKProof, options ...VerifyOption) Report {
+	v2 := Verifier{
+		verificationKey: v.verificationKey,
+		opts:            mergeOptions(false, v.opts, options...),
+	}
+
+	var r Report
+	if err := v2.opts.validate(); err != nil {
+		r.add("options", err, nil, nil)
+		return r.finish()
+	}
+	r.add("options", nil, nil, nil)
+
+	format := v2.validateFormat(proof)
+	r.add("proof", format["zk_proof/proof"], nil, nil)
+	r.add("pub_signals", format["zk_proof/pub_signals"], v2.opts.layout.Count, len(proof.PubSignals))
+	if format["zk_proof/pub_signals"] != nil {
+		for _, name := range []string{
+			"selector", "nullifier", "id_state_root", "event_id", "citizenship", "event_data", "birth_date",
+			"expiration_date_lower_bound", "expiration_date", "identities", "groth16",
+		} {
+			r.skip(name, "public signals are malformed")
+		}
+		return r.finish()
+	}
+
+	v2.explainSignals(ctx, &r, v2.opts.layout.arrange(proof.PubSignals))
+
+	var groth16Err error
+	if err := v2.verifyGroth16(proof); err != nil {
+		groth16Err = verificationErr(fmt.Errorf("groth16 verification failed: %w", err), CodeGroth16Failed, NoSignal, nil, nil)
+	}
+	r.add("groth16", groth16Err, nil, nil)
+
+	return r.finish()
+}
+
+// explainSignals adds the checks of validateBase to the report
+func (v *Verifier) explainSignals(ctx context.Context, r *Report, signals []string) {
+	mask, err := v.validateSelector(signals)
+	if v.opts.proofSelectorValue == "" && err == nil {
+		r.skip("selector", "WithProofSelectorValue is not set")
+	} else {
+		r.add("selector", err, v.opts.proofSelectorValue, signals[Selector])
+	}
+	if err != nil {
+		// the rest of the checks are done with the fields the proof has
+		mask, _ = ParseSelectorMask(signals[Selector])
+	}
+
+	r.add("nullifier", v.validateNullifier(signals), nil, signals[Nullifier])
+
+	err = v.opts.rootVerifier.VerifyRootContext(ctx, signals[IdStateRoot])
+	if !errors.Is(err, identity.ErrContractCall) {
+		err = verificationErr(err, CodeRootInvalid, IdStateRoot, nil, signals[IdStateRoot])
+	}
+	r.add("id_state_root", err, nil, signals[IdStateRoot])
+
+	if v.opts.eventID == "" {
+		r.skip("event_id", "WithEventID is not set")
+	} else {
+		r.add("event_id", v.validateEventID(signals), v.opts.eventID, signals[EventID])
+	}
+
+	if len(v.opts.citizenships) == 0 && len(v.opts.excludedCitizenships) == 0 {
+		r.skip("citizenship", "WithCitizenships and WithCitizenshipsExcluded are not set")
+	} else {
+		r.add("citizenship", v.validateCitizenship(signals, mask), citizenshipExpectation{
+			Allowed:  v.opts.citizenships,
+			Excluded: v.opts.excludedCitizenships,
+		}, canonicalCountry(decodeInt(signals[Citizenship])))
+	}
+
+	if v.opts.eventDataRule == nil {
+		r.skip("event_data", "WithEventData is not set")
+	} else {
+		var expected any
+		if data, ok := v.opts.eventDataRule.(eventData); ok {
+			expected = new(big.Int).SetBytes(data).String()
+		}
+		r.add("event_data", v.validateEventData(signals), expected, signals[EventData])
+	}
+
+	if earliest, latest := v.birthDateRange(); earliest.IsZero() && latest.IsZero() {
+		r.skip("birth_date", "age and birth date options are not set")
+	} else {
+		r.add("birth_date", v.validateBirthDate(signals, mask).Filter(), dateRange{
+			Earliest: nonZeroTime(dateOnly(earliest)),
+			Latest:   nonZeroTime(dateOnly(latest)),
+		}, revealedDates(signals, birthDateDecoder(v.now()), BirthDate, BirthdateLowerBound, BirthdateUpperBound))
+	}
+
+	now := v.now()
+	expiration := v.validatePassportExpiration(signals)
+	decode := expirationDateDecoder(now)
+	for _, signal := range []PubSignal{ExpirationDateLowerBound, ExpirationDate} {
+		name := signal.String()
+		if IsEmptyZKDate(signals[signal]) {
+			r.skip(name, "the date is empty in the proof")
+			continue
+		}
+		r.add(name, expiration["pub_signals/"+name], dateOnly(now), decodeDate(signals[signal], decode))
+	}
+
+	counterSet, timestampSet := v.opts.maxIdentitiesCount != -1, !v.opts.maxIdentityCreationTimestamp.IsZero()
+	if !counterSet && !timestampSet {
+		r.skip("identities", "WithIdentitiesCounter and WithIdentitiesCreationTimestampLimit are not set")
+	} else {
+		expected := identitiesExpectation{Timestamp: nonZeroTime(v.opts.maxIdentityCreationTimestamp)}
+		if counterSet {
+			expected.Counter = &v.opts.maxIdentitiesCount
+		}
+		r.add("identities", v.validateIdentitiesInputs(signals, mask).Filter(), expected, map[string]string{
+			IdentityCounterUpperBound.String(): signals[IdentityCounterUpperBound],
+			TimestampUpperBound.String():       signals[TimestampUpperBound],
+		})
+	}
+}
+
+type (
+	citizenshipExpectation struct {
+		Allowed  []interface{} `json:"allowed,omitempty"`
+		Excluded []interface{} `json:"excluded,omitempty"`
+	}
+
+	// dateRange is inclusive, nil means no limit
+	dateRange struct {
+		Earliest *time.Time `json:"earliest,omitempty"`
+		Latest   *time.Time `json:"latest,omitempty"`
+	}
+
+	identitiesExpectation struct {
+		Counter   *int64     `json:"max_identities_count,omitempty"`
+		Timestamp *time.Time `json:"max_identity_creation_timestamp,omitempty"`
+	}
+)
+
+// add appends the passed or failed check, the code of failure is taken from
+// the first VerificationError of err
+func (r *Report) add(name string, err error, expected, actual any) {
+	c := Check{
+		Name:     name,
+		Status:   CheckPassed,
+		Expected: expected,
+		Actual:   actual,
+	}
+
+	if err != nil {
+		c.Status = CheckFailed
+		c.Message = err.Error()
+
+		// grouped checks, e.g. birth_date, are val.Errors
+		var verr *VerificationError
+		if verrs := VerificationErrors(err); len(verrs) > 0 {
+			c.Code = verrs[0].Code
+		} else if errors.As(err, &verr) {
+			c.Code = verr.Code
+		}
+	}
+
+	r.Checks = append(r.Checks, c)
+}
+
+func (r *Report) skip(name, reason string) {
+	r.Checks = append(r.Checks, Check{Name: name, Status: CheckSkipped, Message: reason})
+}
+
+func (r *Report) finish() Report {
+	r.Valid = true
+	for _, c := range r.Checks {
+		if c.Status == CheckFailed {
+			r.Valid = false
+		}
+	}
+	return *r
+}
+
+// revealedDates decodes the date signals, which are not empty
+func revealedDates(signals []string, decode zkDateDecoder, dates ...PubSignal) map[string]any {
+	revealed := make(map[string]any, len(dates))
+	for _, s := range dates {
+		if signals[s] == "" || IsEmptyZKDate(signals[s]) {
+			continue
+		}
+		revealed[s.String()] = decodeDate(signals[s], decode)
+	}
+	return revealed
+}
+
+// dateOnly truncates the time to the date, as ZK dates have no time
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func nonZeroTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,174 @@
+package zkverifier_kit
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/rarimo/zkverifier-kit/internal/testutil"
+	"github.com/rarimo/zkverifier-kit/nullifier"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifier_Explain(t *testing.T) {
+	key, proof := testutil.Groth16Proof(validProof.PubSignals)
+	store := nullifier.NewMemoryStore()
+
+	verifier, err := NewPassportVerifier(key,
+		WithNow(proofDate),
+		WithProofSelectorValue(validSelector),
+		WithEventID(validEventID),
+		WithCitizenships(ukrCitizenship),
+		WithAgeAbove(equalAge),
+		WithNullifierStore(store),
+	)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name   string
+		proof  func() (key []byte, signals []string)
+		opts   []VerifyOption
+		status map[string]CheckStatus
+		codes  map[string]Code
+	}{
+		{
+			name: "Valid proof",
+			status: map[string]CheckStatus{
+				"options":                     CheckPassed,
+				"selector":                    CheckPassed,
+				"event_id":                    CheckPassed,
+				"citizenship":                 CheckPassed,
+				"event_data":                  CheckSkipped,
+				"birth_date":                  CheckPassed,
+				"expiration_date_lower_bound": CheckPassed,
+				"expiration_date":             CheckSkipped,
+				"identities":                  CheckSkipped,
+				"groth16":                     CheckPassed,
+			},
+		},
+		{
+			name: "All failures are reported",
+			opts: []VerifyOption{WithProofSelectorValue("1"), WithCitizenships("USA"), WithAgeAbove(99)},
+			status: map[string]CheckStatus{
+				"selector":    CheckFailed,
+				"event_id":    CheckPassed,
+				"citizenship": CheckFailed,
+				"birth_date":  CheckFailed,
+				"groth16":     CheckPassed,
+			},
+			codes: map[string]Code{
+				"selector":    CodeSelectorMismatch,
+				"citizenship": CodeCitizenshipNotAllowed,
+				"birth_date":  CodeAgeTooLow,
+			},
+		},
+		{
+			name: "Selector is not set",
+			opts: []VerifyOption{WithProofSelectorValue("")},
+			status: map[string]CheckStatus{
+				"selector":   CheckSkipped,
+				"birth_date": CheckPassed,
+				"groth16":    CheckPassed,
+			},
+		},
+		{
+			name: "Malformed signals",
+			proof: func() ([]byte, []string) {
+				return key, proof.PubSignals[:10]
+			},
+			status: map[string]CheckStatus{
+				"pub_signals": CheckFailed,
+				"selector":    CheckSkipped,
+				"groth16":     CheckSkipped,
+			},
+			codes: map[string]Code{"pub_signals": CodeMalformedProof},
+		},
+		{
+			name: "Invalid options",
+			opts: []VerifyOption{WithCitizenships("ENG")},
+			status: map[string]CheckStatus{
+				"options": CheckFailed,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := proof
+			if tc.proof != nil {
+				_, p.PubSignals = tc.proof()
+			}
+
+			report := verifier.Explain(p, tc.opts...)
+			assert.Equal(t, tc.codes == nil && tc.status["options"] != CheckFailed, report.Valid)
+
+			for name, status := range tc.status {
+				check, ok := report.Check(name)
+				require.True(t, ok, name)
+				assert.Equal(t, status, check.Status, name)
+				assert.Equal(t, tc.codes[name], check.Code, name)
+			}
+		})
+	}
+
+	// Explain must not consume the nullifier
+	require.NoError(t, store.Reserve(context.Background(), proof.PubSignals[EventID], proof.PubSignals[Nullifier]))
+}
+
+func TestVerifier_Explain_Values(t *testing.T) {
+	signals := slices.Clone(validProof.PubSignals)
+	signals[Selector] = "23075"
+	signals[BirthDate] = EncodeZKDate(proofDate.AddDate(-30, 0, 0))
+	key, proof := testutil.Groth16Proof(signals)
+
+	verifier, err := NewPassportVerifier(key,
+		WithNow(proofDate),
+		WithProofSelectorValue("23075"),
+		WithAgeBetween(18, 25),
+		WithCitizenshipsExcluded("RUS"),
+	)
+	require.NoError(t, err)
+
+	report := verifier.Explain(proof)
+	assert.False(t, report.Valid)
+
+	birthDate, ok := report.Check("birth_date")
+	require.True(t, ok)
+	assert.Equal(t, CheckFailed, birthDate.Status)
+	assert.Equal(t, CodeAgeTooHigh, birthDate.Code)
+
+	raw, err := json.Marshal(report)
+	require.NoError(t, err)
+
+	var decoded struct {
+		Valid  bool `json:"valid"`
+		Checks []struct {
+			Name     string `json:"name"`
+			Status   string `json:"status"`
+			Expected any    `json:"expected"`
+			Actual   any    `json:"actual"`
+		} `json:"checks"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	assert.False(t, decoded.Valid)
+
+	for _, c := range decoded.Checks {
+		switch c.Name {
+		case "birth_date":
+			assert.Equal(t, map[string]any{
+				"earliest": "1998-05-25T00:00:00Z",
+				"latest":   "2006-05-24T00:00:00Z",
+			}, c.Expected)
+			assert.Equal(t, map[string]any{
+				"birth_date":             "1994-05-24T00:00:00Z",
+				"birth_date_upper_bound": "2006-05-24T00:00:00Z",
+			}, c.Actual)
+		case "citizenship":
+			assert.Equal(t, "passed", c.Status)
+			assert.Equal(t, map[string]any{"excluded": []any{"RUS"}}, c.Expected)
+			assert.Equal(t, ukrCitizenship, c.Actual)
+		}
+	}
+}
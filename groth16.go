@@ -0,0 +1,268 @@
+package zkverifier_kit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/iden3/go-iden3-crypto/constants"
+	zkptypes "github.com/iden3/go-rapidsnark/types"
+)
+
+var ErrInvalidVerificationKey = errors.New("invalid verification key")
+
+// Groth16Backend implements Groth16 verification over BN254 curve. The key is
+// parsed once with ParseKey and then used for every proof. Use
+// WithGroth16Backend to change the default NewRapidsnarkBackend.
+type Groth16Backend interface {
+	// ParseKey parses the verification key in snarkjs JSON format. It returns
+	// ErrInvalidVerificationKey if the key is malformed.
+	ParseKey(key []byte) (Groth16Key, error)
+}
+
+// Groth16Key is a verification key parsed by Groth16Backend
+type Groth16Key interface {
+	// Verify returns nil if the proof is valid for the key and the public signals
+	Verify(proof zkptypes.ZKProof) error
+}
+
+// groth16KeyJSON is the verification key in snarkjs format
+type groth16KeyJSON struct {
+	Alpha []string   `json:"vk_alpha_1"`
+	Beta  [][]string `json:"vk_beta_2"`
+	Gamma [][]string `json:"vk_gamma_2"`
+	Delta [][]string `json:"vk_delta_2"`
+	IC    [][]string `json:"IC"`
+}
+
+// bn256Point is a point of the bn256 packages of go-rapidsnark and
+// go-ethereum, which have the same API. Unmarshal checks that the point is on
+// the curve.
+type bn256Point interface {
+	Unmarshal([]byte) ([]byte, error)
+}
+
+// bn256G1 is G1 point of the bn256 packages with the group operations
+type bn256G1[G any] interface {
+	bn256Point
+	Add(a, b G) G
+	ScalarMult(a G, k *big.Int) G
+	Neg(a G) G
+}
+
+// bn256Curve decodes keys and proofs into the points of a bn256 package and
+// verifies them, so that the backends only differ in the curve calls
+type bn256Curve[G1 bn256G1[G1], G2 bn256Point] struct {
+	newG1        func() G1
+	newG2        func() G2
+	pairingCheck func([]G1, []G2) bool
+}
+
+// groth16Key is the verification key decoded into the curve points
+type groth16Key[G1 bn256G1[G1], G2 bn256Point] struct {
+	alpha              G1
+	beta, gamma, delta G2
+	ic                 []G1
+}
+
+// groth16Proof is the proof decoded into the curve points, with the public
+// signals checked to be in the field
+type groth16Proof[G1 bn256G1[G1], G2 bn256Point] struct {
+	a, c   G1
+	b      G2
+	inputs []*big.Int
+}
+
+// decodeKey decodes the snarkjs verification key. The decoding is the same as
+// the one of zkpverifier.VerifyGroth16, except that the points at infinity are
+// recognized by zero projective coordinate.
+func (c bn256Curve[G1, G2]) decodeKey(raw []byte) (*groth16Key[G1, G2], error) {
+	var (
+		js  groth16KeyJSON
+		key groth16Key[G1, G2]
+		err error
+	)
+
+	if err = json.Unmarshal(raw, &js); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidVerificationKey, err)
+	}
+
+	if key.alpha, err = c.g1(js.Alpha); err != nil {
+		return nil, fmt.Errorf("%w: vk_alpha_1: %w", ErrInvalidVerificationKey, err)
+	}
+	if key.beta, err = c.g2(js.Beta); err != nil {
+		return nil, fmt.Errorf("%w: vk_beta_2: %w", ErrInvalidVerificationKey, err)
+	}
+	if key.gamma, err = c.g2(js.Gamma); err != nil {
+		return nil, fmt.Errorf("%w: vk_gamma_2: %w", ErrInvalidVerificationKey, err)
+	}
+	if key.delta, err = c.g2(js.Delta); err != nil {
+		return nil, fmt.Errorf("%w: vk_delta_2: %w", ErrInvalidVerificationKey, err)
+	}
+
+	if len(js.IC) == 0 {
+		return nil, fmt.Errorf("%w: IC is empty", ErrInvalidVerificationKey)
+	}
+	key.ic = make([]G1, len(js.IC))
+	for i, p := range js.IC {
+		if key.ic[i], err = c.g1(p); err != nil {
+			return nil, fmt.Errorf("%w: IC[%d]: %w", ErrInvalidVerificationKey, i, err)
+		}
+	}
+
+	return &key, nil
+}
+
+// decodeProof decodes the proof for the key with icLen IC points
+func (c bn256Curve[G1, G2]) decodeProof(proof zkptypes.ZKProof, icLen int) (*groth16Proof[G1, G2], error) {
+	if proof.Proof == nil {
+		return nil, errors.New("proof data is missing")
+	}
+
+	var (
+		p   groth16Proof[G1, G2]
+		err error
+	)
+
+	if p.a, err = c.g1(proof.Proof.A); err != nil {
+		return nil, fmt.Errorf("pi_a: %w", err)
+	}
+	if p.b, err = c.g2(proof.Proof.B); err != nil {
+		return nil, fmt.Errorf("pi_b: %w", err)
+	}
+	if p.c, err = c.g1(proof.Proof.C); err != nil {
+		return nil, fmt.Errorf("pi_c: %w", err)
+	}
+
+	if len(proof.PubSignals)+1 != icLen {
+		return nil, fmt.Errorf("expected %d public signals, got %d", icLen-1, len(proof.PubSignals))
+	}
+
+	p.inputs = make([]*big.Int, len(proof.PubSignals))
+	for i, s := range proof.PubSignals {
+		if p.inputs[i], err = ParseFieldElement(s); err != nil {
+			return nil, fmt.Errorf("public signal %d: %w", i, err)
+		}
+		if p.inputs[i].Cmp(constants.Q) >= 0 {
+			return nil, fmt.Errorf("public signal %d is not in the field", i)
+		}
+	}
+
+	return &p, nil
+}
+
+// verify checks the proof against the parsed key:
+// e(A, B) = e(alpha, beta) * e(vk_x, gamma) * e(C, delta)
+func (c bn256Curve[G1, G2]) verify(key *groth16Key[G1, G2], proof zkptypes.ZKProof) error {
+	p, err := c.decodeProof(proof, len(key.ic))
+	if err != nil {
+		return err
+	}
+
+	vkX := key.ic[0]
+	for i, input := range p.inputs {
+		vkX = c.newG1().Add(vkX, c.newG1().ScalarMult(key.ic[i+1], input))
+	}
+
+	g1 := []G1{p.a, c.newG1().Neg(key.alpha), c.newG1().Neg(vkX), c.newG1().Neg(p.c)}
+	g2 := []G2{p.b, key.beta, key.gamma, key.delta}
+
+	if !c.pairingCheck(g1, g2) {
+		return errors.New("invalid proof")
+	}
+
+	return nil
+}
+
+func (c bn256Curve[G1, G2]) g1(coords []string) (G1, error) {
+	p := c.newG1()
+	buf, err := encodeG1(coords)
+	if err == nil {
+		_, err = p.Unmarshal(buf)
+	}
+	return p, err
+}
+
+func (c bn256Curve[G1, G2]) g2(coords [][]string) (G2, error) {
+	p := c.newG2()
+	buf, err := encodeG2(coords)
+	if err == nil {
+		_, err = p.Unmarshal(buf)
+	}
+	return p, err
+}
+
+// ParseFieldElement parses non-negative decimal or 0x-prefixed hex number, as
+// the numbers of proofs and verification keys are encoded. The range is not
+// checked.
+func ParseFieldElement(s string) (*big.Int, error) {
+	base := 10
+	if hexStr, ok := strings.CutPrefix(strings.ToLower(s), "0x"); ok {
+		s, base = hexStr, 16
+	}
+
+	n, ok := new(big.Int).SetString(s, base)
+	if !ok || n.Sign() < 0 {
+		return nil, fmt.Errorf("invalid field element %q", s)
+	}
+
+	return n, nil
+}
+
+// encodeG1 encodes projective [x, y, z] point into the uncompressed form, which
+// is accepted by Unmarshal of the bn256 packages. z is only checked for zero.
+func encodeG1(coords []string) ([]byte, error) {
+	if len(coords) != 3 {
+		return nil, fmt.Errorf("G1 point must have 3 coordinates, got %d", len(coords))
+	}
+
+	buf := make([]byte, 64)
+	if coords[2] != "0" {
+		if err := putFieldElements(buf, coords[0], coords[1]); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf, nil
+}
+
+// encodeG2 encodes projective [[x0, x1], [y0, y1], [z0, z1]] point, z is only
+// checked for zero. bn256 expects the imaginary parts first.
+func encodeG2(coords [][]string) ([]byte, error) {
+	if len(coords) != 3 {
+		return nil, fmt.Errorf("G2 point must have 3 coordinates, got %d", len(coords))
+	}
+	for _, c := range coords {
+		if len(c) != 2 {
+			return nil, fmt.Errorf("G2 coordinate must have 2 elements, got %d", len(c))
+		}
+	}
+
+	buf := make([]byte, 128)
+	if coords[2][0] != "0" || coords[2][1] != "0" {
+		x, y := coords[0], coords[1]
+		if err := putFieldElements(buf, x[1], x[0], y[1], y[0]); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf, nil
+}
+
+// putFieldElements writes the elements into buf as 32-byte big-endian numbers
+func putFieldElements(buf []byte, elems ...string) error {
+	for i, e := range elems {
+		n, err := ParseFieldElement(e)
+		if err != nil {
+			return err
+		}
+		if n.BitLen() > 256 {
+			return fmt.Errorf("field element %s is too large", e)
+		}
+		n.FillBytes(buf[i*32 : (i+1)*32])
+	}
+
+	return nil
+}
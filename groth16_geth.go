@@ -0,0 +1,38 @@
+package zkverifier_kit
+
+import (
+	"github.com/ethereum/go-ethereum/crypto/bn256"
+	zkptypes "github.com/iden3/go-rapidsnark/types"
+)
+
+type gethBackend struct{}
+
+// NewGethBackend returns Groth16Backend, which uses the pairing code of
+// go-ethereum crypto/bn256. It gives the same results as NewRapidsnarkBackend,
+// so it can be used to cross-check them or to drop go-rapidsnark verifier.
+func NewGethBackend() Groth16Backend {
+	return gethBackend{}
+}
+
+var gethCurve = bn256Curve[*bn256.G1, *bn256.G2]{
+	newG1:        func() *bn256.G1 { return new(bn256.G1) },
+	newG2:        func() *bn256.G2 { return new(bn256.G2) },
+	pairingCheck: bn256.PairingCheck,
+}
+
+type gethKey struct {
+	*groth16Key[*bn256.G1, *bn256.G2]
+}
+
+func (gethBackend) ParseKey(raw []byte) (Groth16Key, error) {
+	key, err := gethCurve.decodeKey(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return gethKey{key}, nil
+}
+
+func (k gethKey) Verify(proof zkptypes.ZKProof) error {
+	return gethCurve.verify(k.groth16Key, proof)
+}
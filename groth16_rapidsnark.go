@@ -0,0 +1,37 @@
+package zkverifier_kit
+
+import (
+	zkptypes "github.com/iden3/go-rapidsnark/types"
+	"github.com/iden3/go-rapidsnark/verifier/bn256"
+)
+
+type rapidsnarkBackend struct{}
+
+// NewRapidsnarkBackend returns the default Groth16Backend, which uses the
+// pairing code of github.com/iden3/go-rapidsnark/verifier
+func NewRapidsnarkBackend() Groth16Backend {
+	return rapidsnarkBackend{}
+}
+
+var rapidsnarkCurve = bn256Curve[*bn256.G1, *bn256.G2]{
+	newG1:        func() *bn256.G1 { return new(bn256.G1) },
+	newG2:        func() *bn256.G2 { return new(bn256.G2) },
+	pairingCheck: bn256.PairingCheck,
+}
+
+type rapidsnarkKey struct {
+	*groth16Key[*bn256.G1, *bn256.G2]
+}
+
+func (rapidsnarkBackend) ParseKey(raw []byte) (Groth16Key, error) {
+	key, err := rapidsnarkCurve.decodeKey(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return rapidsnarkKey{key}, nil
+}
+
+func (k rapidsnarkKey) Verify(proof zkptypes.ZKProof) error {
+	return rapidsnarkCurve.verify(k.groth16Key, proof)
+}
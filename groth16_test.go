@@ -0,0 +1,175 @@
+package zkverifier_kit
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	zkptypes "github.com/iden3/go-rapidsnark/types"
+	zkpverifier "github.com/iden3/go-rapidsnark/verifier"
+	"github.com/rarimo/zkverifier-kit/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mismatchedKey returns a well-formed verification key, which does not match
+// any proof of the circuit, because IC[0] and IC[ic] are swapped
+func mismatchedKey(t testing.TB, ic int) []byte {
+	return modifyKey(t, func(key map[string]any) {
+		points := key["IC"].([]any)
+		points[0], points[ic] = points[ic], points[0]
+	})
+}
+
+// modifyKey returns the copy of verificationKey changed by modify
+func modifyKey(t testing.TB, modify func(key map[string]any)) []byte {
+	var key map[string]any
+	require.NoError(t, json.Unmarshal(verificationKey, &key))
+	modify(key)
+
+	raw, err := json.Marshal(key)
+	require.NoError(t, err)
+	return raw
+}
+
+var groth16Backends = []struct {
+	name    string
+	backend Groth16Backend
+}{
+	{name: "rapidsnark", backend: NewRapidsnarkBackend()},
+	{name: "geth", backend: NewGethBackend()},
+}
+
+func TestGroth16Backend_Verify(t *testing.T) {
+	rawKey, proof := testutil.Groth16Proof(validProof.PubSignals)
+
+	tampered := proof
+	tampered.PubSignals = slices.Clone(proof.PubSignals)
+	tampered.PubSignals[Citizenship] = "5591873"
+
+	short := proof
+	short.PubSignals = proof.PubSignals[:10]
+
+	testCases := []struct {
+		name  string
+		proof zkptypes.ZKProof
+		valid bool
+	}{
+		{name: "Valid proof", proof: proof, valid: true},
+		{name: "Tampered signal", proof: tampered},
+		{name: "Signals count mismatch", proof: short},
+		{name: "Fixture proof", proof: validProof},
+	}
+
+	for _, b := range groth16Backends {
+		key, err := b.backend.ParseKey(rawKey)
+		require.NoError(t, err)
+
+		for _, tc := range testCases {
+			t.Run(b.name+"/"+tc.name, func(t *testing.T) {
+				err := key.Verify(tc.proof)
+				if tc.valid {
+					assert.NoError(t, err)
+				} else {
+					assert.Error(t, err)
+				}
+
+				// the results must be the same as the ones of rapidsnark
+				assert.Equal(t, err == nil, zkpverifier.VerifyGroth16(tc.proof, rawKey) == nil)
+			})
+		}
+	}
+}
+
+func TestGroth16Backend_ParseKey(t *testing.T) {
+	testCases := []struct {
+		name string
+		key  []byte
+		want string
+	}{
+		{name: "Valid key", key: verificationKey},
+		{name: "Mismatched key", key: mismatchedKey(t, 1)},
+		{name: "Not JSON", key: []byte("key"), want: "invalid character"},
+		{name: "Missing field", key: bytes.Replace(verificationKey, []byte("vk_alpha_1"), []byte("vk_alpha"), 1), want: "vk_alpha_1"},
+		{name: "Point not on curve", key: bytes.Replace(verificationKey, []byte(`"20491192805390485299153009773594534940189261866228447918068658471970481763042"`), []byte(`"1"`), 1), want: "vk_alpha_1"},
+		{name: "Empty IC", key: []byte(`{"vk_alpha_1":["0","1","0"],"vk_beta_2":[["0","0"],["1","0"],["0","0"]],"vk_gamma_2":[["0","0"],["1","0"],["0","0"]],"vk_delta_2":[["0","0"],["1","0"],["0","0"]]}`), want: "IC is empty"},
+	}
+
+	for _, b := range groth16Backends {
+		for _, tc := range testCases {
+			t.Run(b.name+"/"+tc.name, func(t *testing.T) {
+				_, err := b.backend.ParseKey(tc.key)
+				if tc.want == "" {
+					assert.NoError(t, err)
+					return
+				}
+
+				assert.ErrorIs(t, err, ErrInvalidVerificationKey)
+				assert.ErrorContains(t, err, tc.want)
+			})
+		}
+	}
+}
+
+func TestWithGroth16Backend(t *testing.T) {
+	rawKey, proof := testutil.Groth16Proof(validProof.PubSignals)
+
+	for _, b := range groth16Backends {
+		t.Run(b.name, func(t *testing.T) {
+			verifier, err := NewPassportVerifier(rawKey,
+				WithNow(proofDate),
+				WithProofSelectorValue(validSelector),
+				WithGroth16Backend(b.backend),
+			)
+			require.NoError(t, err)
+			assert.NoError(t, verifier.VerifyProof(proof))
+
+			registry := NewKeyRegistryWithBackend(b.backend)
+			require.NoError(t, registry.Register("v1", rawKey))
+			verifier, err = NewPassportVerifier(nil, WithNow(proofDate), WithProofSelectorValue(validSelector), WithKeyRegistry(registry))
+			require.NoError(t, err)
+			assert.NoError(t, verifier.VerifyProof(proof))
+		})
+	}
+}
+
+func TestNewPassportVerifier_ParsedKey(t *testing.T) {
+	verifier, err := NewPassportVerifier(verificationKey)
+	require.NoError(t, err)
+
+	// the default backend keeps the decoded points instead of the key JSON
+	key, ok := verifier.verificationKey.key().(rapidsnarkKey)
+	require.True(t, ok)
+	assert.Len(t, key.ic, DefaultSignalLayout().Count+1)
+}
+
+// BenchmarkVerifyGroth16 compares the verification with the key parsed on each
+// call by rapidsnark and with the key parsed once by each backend
+func BenchmarkVerifyGroth16(b *testing.B) {
+	rawKey, proof := testutil.Groth16Proof(validProof.PubSignals)
+
+	b.Run("raw key", func(b *testing.B) {
+		for range b.N {
+			if err := zkpverifier.VerifyGroth16(proof, rawKey); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	for _, backend := range groth16Backends {
+		b.Run("parsed key/"+backend.name, func(b *testing.B) {
+			key, err := backend.backend.ParseKey(rawKey)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ResetTimer()
+
+			for range b.N {
+				if err := key.Verify(proof); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package identity
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"golang.org/x/sync/singleflight"
+)
+
+const defaultCacheSize = 10000
+
+// RootVerifier is implemented by Verifier and can be decorated with CachedVerifier
+type RootVerifier interface {
+	VerifyRootContext(ctx context.Context, root string) error
+}
+
+// ValidityCaller is an abstract contract caller, which provides the period
+// during which the root stays valid after it was replaced by a newer one
+type ValidityCaller interface {
+	ROOTVALIDITY(opts *bind.CallOpts) (*big.Int, error)
+}
+
+// CacheConfig sets up CachedVerifier
+type CacheConfig struct {
+	// TTL is the lifetime of valid roots in cache. It is capped by the root
+	// validity period of the contract. Zero means the root validity period.
+	TTL time.Duration `fig:"ttl"`
+	// NegativeTTL is the lifetime of invalid roots in cache. Zero disables
+	// caching of invalid roots.
+	NegativeTTL time.Duration `fig:"negative_ttl"`
+	// MaxSize is the maximum amount of cached roots, both valid and invalid.
+	// Zero means the default size.
+	MaxSize int `fig:"max_size"`
+}
+
+// CachedVerifier remembers the results of root verification, so that the
+// contract is not called for every proof with the same root. Concurrent
+// verifications of the same uncached root share a single contract call. When
+// the cache is full, the least recently used root is evicted. Contract call
+// errors are never cached.
+//
+// Keep in mind that a valid root may be cached up to the root validity period,
+// even if it became invalid earlier. This is the price for fewer RPC calls.
+type CachedVerifier struct {
+	next        RootVerifier
+	ttl         time.Duration
+	negativeTTL time.Duration
+	maxSize     int
+	now         func() time.Time
+
+	calls singleflight.Group
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// lru holds *cacheEntry, the most recently used is at front
+	lru *list.List
+}
+
+type cacheEntry struct {
+	root      string
+	err       error
+	expiresAt time.Time
+}
+
+// NewCachedVerifier wraps the verifier with cache. The rootValidity is the value
+// of ROOT_VALIDITY from the contract, see RootValidity. Both positive and
+// negative TTL are capped by it.
+func NewCachedVerifier(next RootVerifier, rootValidity time.Duration, cfg CacheConfig) *CachedVerifier {
+	if cfg.TTL <= 0 || cfg.TTL > rootValidity {
+		cfg.TTL = rootValidity
+	}
+	if cfg.NegativeTTL > rootValidity {
+		cfg.NegativeTTL = rootValidity
+	}
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = defaultCacheSize
+	}
+
+	return &CachedVerifier{
+		next:        next,
+		ttl:         cfg.TTL,
+		negativeTTL: cfg.NegativeTTL,
+		maxSize:     cfg.MaxSize,
+		now:         time.Now,
+		entries:     make(map[string]*list.Element),
+		lru:         list.New(),
+	}
+}
+
+// RootValidity calls the contract to get ROOT_VALIDITY in seconds
+func RootValidity(ctx context.Context, caller ValidityCaller) (time.Duration, error) {
+	validity, err := caller.ROOTVALIDITY(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrContractCall, err)
+	}
+	if !validity.IsInt64() || validity.Sign() <= 0 {
+		return 0, fmt.Errorf("unexpected root validity %s", validity)
+	}
+
+	return time.Duration(validity.Int64()) * time.Second, nil
+}
+
+// VerifyRoot is the same as Verifier.VerifyRoot, but cached
+func (v *CachedVerifier) VerifyRoot(root string) error {
+	return v.VerifyRootContext(context.Background(), root)
+}
+
+// VerifyRootContext is the same as Verifier.VerifyRootContext, but cached. The
+// shared contract call is not canceled with ctx, as other callers may wait for
+// it, but the caller returns as soon as ctx is done.
+func (v *CachedVerifier) VerifyRootContext(ctx context.Context, root string) error {
+	if err, ok := v.get(root); ok {
+		return err
+	}
+
+	ch := v.calls.DoChan(root, func() (interface{}, error) {
+		// the root could be cached while the previous call was finishing
+		if err, ok := v.get(root); ok {
+			return nil, err
+		}
+
+		err := v.next.VerifyRootContext(context.WithoutCancel(ctx), root)
+		switch {
+		case err == nil:
+			v.put(root, nil, v.ttl)
+		case errors.Is(err, ErrInvalidRoot) && v.negativeTTL > 0:
+			v.put(root, err, v.negativeTTL)
+		}
+
+		return nil, err
+	})
+
+	select {
+	case res := <-ch:
+		return res.Err
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %w", ErrContractCall, ctx.Err())
+	}
+}
+
+func (v *CachedVerifier) get(root string) (error, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	elem, ok := v.entries[root]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	if !v.now().Before(entry.expiresAt) {
+		v.remove(elem)
+		return nil, false
+	}
+
+	v.lru.MoveToFront(elem)
+	return entry.err, true
+}
+
+func (v *CachedVerifier) put(root string, err error, ttl time.Duration) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	entry := &cacheEntry{root: root, err: err, expiresAt: v.now().Add(ttl)}
+	if elem, ok := v.entries[root]; ok {
+		elem.Value = entry
+		v.lru.MoveToFront(elem)
+		return
+	}
+
+	if v.lru.Len() >= v.maxSize {
+		v.remove(v.lru.Back())
+	}
+	v.entries[root] = v.lru.PushFront(entry)
+}
+
+// remove deletes the entry from cache. Must be called under lock.
+func (v *CachedVerifier) remove(elem *list.Element) {
+	v.lru.Remove(elem)
+	delete(v.entries, elem.Value.(*cacheEntry).root)
+}
@@ -0,0 +1,185 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingVerifier struct {
+	calls int
+	err   error
+}
+
+func (v *countingVerifier) VerifyRootContext(_ context.Context, _ string) error {
+	v.calls++
+	return v.err
+}
+
+func TestCachedVerifier_VerifyRoot(t *testing.T) {
+	const root = "16693841514009401027717517576091902513189966508499657428478303854796486502473"
+
+	testCases := []struct {
+		name      string
+		err       error
+		cfg       CacheConfig
+		validity  time.Duration
+		elapsed   time.Duration
+		wantCalls int
+	}{
+		{
+			name:      "Valid root is cached",
+			cfg:       CacheConfig{TTL: time.Minute},
+			validity:  time.Hour,
+			elapsed:   59 * time.Second,
+			wantCalls: 1,
+		},
+		{
+			name:      "Valid root expires after TTL",
+			cfg:       CacheConfig{TTL: time.Minute},
+			validity:  time.Hour,
+			elapsed:   time.Minute,
+			wantCalls: 2,
+		},
+		{
+			name:      "TTL is capped by root validity",
+			cfg:       CacheConfig{TTL: time.Hour},
+			validity:  time.Minute,
+			elapsed:   time.Minute,
+			wantCalls: 2,
+		},
+		{
+			name:      "Invalid root is cached with negative TTL",
+			err:       ErrInvalidRoot,
+			cfg:       CacheConfig{NegativeTTL: time.Minute},
+			validity:  time.Hour,
+			elapsed:   time.Second,
+			wantCalls: 1,
+		},
+		{
+			name:      "Invalid root is not cached without negative TTL",
+			err:       ErrInvalidRoot,
+			validity:  time.Hour,
+			elapsed:   time.Second,
+			wantCalls: 2,
+		},
+		{
+			name:      "Contract call error is not cached",
+			err:       errors.Join(ErrContractCall, errors.New("rpc is down")),
+			cfg:       CacheConfig{NegativeTTL: time.Minute},
+			validity:  time.Hour,
+			elapsed:   time.Second,
+			wantCalls: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			next := &countingVerifier{err: tc.err}
+			now := time.Now()
+
+			v := NewCachedVerifier(next, tc.validity, tc.cfg)
+			v.now = func() time.Time { return now }
+
+			assert.ErrorIs(t, v.VerifyRoot(root), tc.err)
+			now = now.Add(tc.elapsed)
+			assert.ErrorIs(t, v.VerifyRoot(root), tc.err)
+			assert.Equal(t, tc.wantCalls, next.calls)
+		})
+	}
+}
+
+func TestCachedVerifier_MaxSize(t *testing.T) {
+	next := new(countingVerifier)
+	v := NewCachedVerifier(next, time.Hour, CacheConfig{MaxSize: 2})
+
+	for _, root := range []string{"1", "2", "3"} {
+		assert.NoError(t, v.VerifyRoot(root))
+	}
+	assert.Len(t, v.entries, 2)
+	assert.Equal(t, 3, next.calls)
+
+	// the least recently used root is evicted: "1" at first, and "3" after
+	// "2" is used again
+	assert.NoError(t, v.VerifyRoot("2"))
+	assert.Equal(t, 3, next.calls)
+	assert.NoError(t, v.VerifyRoot("1"))
+	assert.Equal(t, 4, next.calls)
+	assert.NoError(t, v.VerifyRoot("2"))
+	assert.Equal(t, 4, next.calls)
+	assert.NoError(t, v.VerifyRoot("3"))
+	assert.Equal(t, 5, next.calls)
+	assert.Len(t, v.entries, 2)
+}
+
+// blockingVerifier counts the calls and blocks them until release is closed
+type blockingVerifier struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (v *blockingVerifier) VerifyRootContext(_ context.Context, _ string) error {
+	v.calls.Add(1)
+	<-v.release
+	return nil
+}
+
+func TestCachedVerifier_Concurrent(t *testing.T) {
+	const root = "1"
+	next := &blockingVerifier{release: make(chan struct{})}
+	v := NewCachedVerifier(next, time.Hour, CacheConfig{})
+
+	// the waiting caller gives up, but the shared call goes on
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, v.VerifyRootContext(ctx, root), ErrContractCall)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, v.VerifyRoot(root))
+		}()
+	}
+
+	// let the callers join the call before it is finished
+	time.Sleep(10 * time.Millisecond)
+	close(next.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), next.calls.Load())
+	assert.NoError(t, v.VerifyRoot(root))
+	assert.Equal(t, int32(1), next.calls.Load())
+}
+
+type mapGetter map[string]interface{}
+
+func (g mapGetter) GetStringMap(key string) (map[string]interface{}, error) {
+	m, _ := g[key].(map[string]interface{})
+	return m, nil
+}
+
+func TestVerifierProvider_ProvideCachedVerifier(t *testing.T) {
+	getter := mapGetter{
+		"root_verifier": map[string]interface{}{
+			"disabled": true,
+			"cache": map[string]interface{}{
+				"ttl":          "1m",
+				"negative_ttl": "10s",
+				"max_size":     100,
+			},
+		},
+	}
+
+	v := NewVerifierProvider(getter).ProvideCachedVerifier()
+	assert.Equal(t, 100, v.maxSize)
+	// disabled verifier has no root validity period, so nothing is cached
+	assert.Zero(t, v.ttl)
+	assert.NoError(t, v.VerifyRoot("0x1234"))
+}
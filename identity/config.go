@@ -1,6 +1,7 @@
 package identity
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -15,14 +16,16 @@ import (
 const baseTimeout = 5 * time.Second
 
 type VerifierProvider struct {
-	once   *comfig.Once
-	getter kv.Getter
+	once       *comfig.Once
+	cachedOnce *comfig.Once
+	getter     kv.Getter
 }
 
 func NewVerifierProvider(getter kv.Getter) VerifierProvider {
 	return VerifierProvider{
-		getter: getter,
-		once:   new(comfig.Once),
+		getter:     getter,
+		once:       new(comfig.Once),
+		cachedOnce: new(comfig.Once),
 	}
 }
 
@@ -70,6 +73,44 @@ func (c VerifierProvider) ProvideVerifier() *Verifier {
 			panic(fmt.Errorf("failed to bind registration contract caller: %w", err))
 		}
 
-		return NewVerifier(caller, cfg.RequestTimeout)
+		return NewVerifier(caller, cfg.RequestTimeout).WithEndpoint(cfg.RPC, cfg.Contract)
 	}).(*Verifier)
 }
+
+// ProvideCachedVerifier wraps the verifier from ProvideVerifier with
+// CachedVerifier, configured in `cache` section of root_verifier. Root validity
+// period is requested from the contract on the first call.
+func (c VerifierProvider) ProvideCachedVerifier() *CachedVerifier {
+	return c.cachedOnce.Do(func() interface{} {
+		var cfg struct {
+			Cache CacheConfig `fig:"cache"`
+		}
+
+		err := figure.Out(&cfg).
+			From(kv.MustGetStringMap(c.getter, "root_verifier")).
+			Please()
+		if err != nil {
+			panic(fmt.Errorf("failed to figure out root_verifier cache: %s", err))
+		}
+
+		verifier := c.ProvideVerifier()
+		if verifier.IsDisabled() {
+			return NewCachedVerifier(verifier, 0, cfg.Cache)
+		}
+
+		caller, ok := verifier.caller.(ValidityCaller)
+		if !ok {
+			panic(fmt.Errorf("contract caller %T does not provide root validity", verifier.caller))
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), verifier.timeout)
+		defer cancel()
+
+		validity, err := RootValidity(ctx, caller)
+		if err != nil {
+			panic(fmt.Errorf("failed to get root validity: %w", err))
+		}
+
+		return NewCachedVerifier(verifier, validity, cfg.Cache)
+	}).(*CachedVerifier)
+}
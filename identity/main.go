@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var (
@@ -25,6 +26,8 @@ type Verifier struct {
 	caller   Caller
 	timeout  time.Duration
 	disabled bool
+	// attrs describe the contract in spans, see WithEndpoint
+	attrs []attribute.KeyValue
 }
 
 // Caller is an abstract contract caller, which verifiers identity root validity
@@ -49,10 +52,21 @@ func NewDisabledVerifier() *Verifier {
 //
 // If Verifier is disabled, nil is always returned.
 func (v *Verifier) VerifyRoot(root string) error {
+	return v.VerifyRootContext(context.Background(), root)
+}
+
+// VerifyRootContext is the same as VerifyRoot, but the contract call is bound
+// to the provided context. The verifier timeout, when positive, still applies
+// as an upper bound. When ctx carries a span, the call is traced in its child
+// span.
+func (v *Verifier) VerifyRootContext(ctx context.Context, root string) (err error) {
 	if v.disabled {
 		return nil
 	}
 
+	ctx, span := v.startSpan(ctx, root)
+	defer func() { endSpan(span, err) }()
+
 	b, ok := new(big.Int).SetString(root, 10)
 	if !ok {
 		return ErrInvalidRoot
@@ -61,8 +75,11 @@ func (v *Verifier) VerifyRoot(root string) error {
 	var provided [32]byte
 	copy(provided[:], b.Bytes())
 
-	ctx, cancel := context.WithTimeout(context.Background(), v.timeout)
-	defer cancel()
+	if v.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, v.timeout)
+		defer cancel()
+	}
 
 	valid, err := v.caller.IsRootValid(&bind.CallOpts{Context: ctx}, provided)
 	if err != nil {
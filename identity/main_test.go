@@ -1,7 +1,9 @@
 package identity
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/rarimo/zkverifier-kit/internal/testutil"
 	"github.com/stretchr/testify/assert"
@@ -50,3 +52,21 @@ func TestVerifier_VerifyRoot(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifier_VerifyRootContext(t *testing.T) {
+	const (
+		providedRoot = "16693841514009401027717517576091902513189966508499657428478303854796486502473"
+		storedRoot   = "24e861243940eb879c33d91d1312bd0f7b44887342739eb210bdb30c01186849"
+	)
+
+	v := NewVerifier(new(testutil.MockCaller).WithRoot(storedRoot), time.Second)
+	assert.NoError(t, v.VerifyRootContext(context.Background(), providedRoot))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := v.VerifyRootContext(ctx, providedRoot)
+	assert.ErrorIs(t, err, ErrContractCall)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	assert.NoError(t, NewDisabledVerifier().VerifyRootContext(ctx, providedRoot))
+}
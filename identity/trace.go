@@ -0,0 +1,59 @@
+package identity
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/rarimo/zkverifier-kit/identity"
+
+// WithEndpoint describes the contract, which is called by the verifier, for
+// tracing: the RPC endpoint and the contract address are set as attributes of
+// the VerifyRoot spans. Only the scheme and the host of the endpoint are kept,
+// because the rest of URL may contain API keys.
+func (v *Verifier) WithEndpoint(rpc string, contract common.Address) *Verifier {
+	v.attrs = []attribute.KeyValue{
+		attribute.String("rpc.endpoint", redactEndpoint(rpc)),
+		attribute.String("contract.address", contract.Hex()),
+	}
+	return v
+}
+
+// startSpan starts the child span of the one from ctx with its tracer
+// provider, so the span is recorded only when the caller is traced, e.g. with
+// WithTracerProvider option of the kit
+func (v *Verifier) startSpan(ctx context.Context, root string) (context.Context, trace.Span) {
+	return trace.SpanFromContext(ctx).TracerProvider().Tracer(tracerName).Start(ctx, "identity.VerifyRoot",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(append(v.attrs, attribute.String("identity.root", root))...),
+	)
+}
+
+// endSpan records the error as span event and sets the status, invalid root
+// is not considered as span failure, because the call succeeded
+func endSpan(span trace.Span, err error) {
+	switch {
+	case err == nil:
+		span.SetAttributes(attribute.Bool("identity.root_valid", true))
+	case errors.Is(err, ErrInvalidRoot):
+		span.SetAttributes(attribute.Bool("identity.root_valid", false))
+	default:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, ErrContractCall.Error())
+	}
+	span.End()
+}
+
+func redactEndpoint(rpc string) string {
+	u, err := url.Parse(rpc)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
@@ -0,0 +1,98 @@
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/iden3/go-iden3-crypto/constants"
+	zkptypes "github.com/iden3/go-rapidsnark/types"
+	"github.com/iden3/go-rapidsnark/verifier/bn256"
+)
+
+// Groth16Proof generates a verification key with known secret scalars and a
+// proof of the public signals, which is valid for this key. There is no circuit
+// behind them, the proof only satisfies the Groth16 pairing equation:
+//
+//	e(A, B) = e(alpha, beta) * e(vkX, gamma) * e(C, delta)
+//
+// It is used to test the verification of valid proofs, since the key and the
+// proof in testdata do not match.
+func Groth16Proof(signals []string) (key []byte, proof zkptypes.ZKProof) {
+	var (
+		q                  = constants.Q
+		alpha, beta, gamma = big.NewInt(3), big.NewInt(5), big.NewInt(7)
+		delta, a           = big.NewInt(11), big.NewInt(13)
+		ic                 = make([]*big.Int, len(signals)+1)
+		icPoints           = make([][]string, len(signals)+1)
+		vkX                = new(big.Int)
+		mul                = func(x, y *big.Int) *big.Int { return new(big.Int).Mod(new(big.Int).Mul(x, y), q) }
+		g1                 = func(k *big.Int) []string { return g1ToStrings(new(bn256.G1).ScalarBaseMult(k)) }
+		g2                 = func(k *big.Int) [][]string { return g2ToStrings(new(bn256.G2).ScalarBaseMult(k)) }
+	)
+
+	for i := range ic {
+		ic[i] = big.NewInt(int64(17 + i))
+		icPoints[i] = g1(ic[i])
+	}
+
+	vkX.Set(ic[0])
+	for i, s := range signals {
+		n, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			panic(fmt.Errorf("invalid public signal %q", s))
+		}
+		vkX.Add(vkX, mul(n, ic[i+1]))
+	}
+
+	// A = a*G1, B = G2, so a = alpha*beta + vkX*gamma + c*delta
+	c := new(big.Int).Sub(a, mul(alpha, beta))
+	c.Sub(c, mul(vkX, gamma))
+	c = mul(c, new(big.Int).ModInverse(delta, q))
+
+	key, err := json.Marshal(map[string]any{
+		"protocol":   "groth16",
+		"curve":      "bn128",
+		"nPublic":    len(signals),
+		"vk_alpha_1": g1(alpha),
+		"vk_beta_2":  g2(beta),
+		"vk_gamma_2": g2(gamma),
+		"vk_delta_2": g2(delta),
+		"IC":         icPoints,
+	})
+	if err != nil {
+		panic(fmt.Errorf("failed to marshal verification key: %w", err))
+	}
+
+	return key, zkptypes.ZKProof{
+		Proof: &zkptypes.ProofData{
+			A:        g1(a),
+			B:        g2(big.NewInt(1)),
+			C:        g1(c),
+			Protocol: "groth16",
+		},
+		PubSignals: append([]string(nil), signals...),
+	}
+}
+
+// g1ToStrings encodes the point in snarkjs format: [x, y, 1]
+func g1ToStrings(p *bn256.G1) []string {
+	m := p.Marshal()
+	return []string{
+		new(big.Int).SetBytes(m[:32]).String(),
+		new(big.Int).SetBytes(m[32:]).String(),
+		"1",
+	}
+}
+
+// g2ToStrings encodes the point in snarkjs format: [[x0, x1], [y0, y1], [1, 0]],
+// while bn256 marshals the imaginary parts first
+func g2ToStrings(p *bn256.G2) [][]string {
+	m := p.Marshal()
+	elem := func(i int) string { return new(big.Int).SetBytes(m[i*32 : (i+1)*32]).String() }
+	return [][]string{
+		{elem(1), elem(0)},
+		{elem(3), elem(2)},
+		{"1", "0"},
+	}
+}
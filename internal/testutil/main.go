@@ -18,7 +18,13 @@ func (m *MockCaller) WithRoot(root string) *MockCaller {
 	}
 }
 
-func (m *MockCaller) IsRootValid(_ *bind.CallOpts, root [32]byte) (bool, error) {
+func (m *MockCaller) IsRootValid(opts *bind.CallOpts, root [32]byte) (bool, error) {
+	if opts != nil && opts.Context != nil {
+		if err := opts.Context.Err(); err != nil {
+			return false, err
+		}
+	}
+
 	return bytes.Equal(root[:], m.root), nil
 }
 
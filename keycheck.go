@@ -0,0 +1,87 @@
+package zkverifier_kit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrVerificationKeyHashMismatch = errors.New("verification key hash mismatch")
+
+const (
+	keyProtocol = "groth16"
+	keyCurve    = "bn128"
+)
+
+// keyMetadata is the part of snarkjs verification key, which describes the
+// proving system and the circuit
+type keyMetadata struct {
+	Protocol string            `json:"protocol"`
+	Curve    string            `json:"curve"`
+	NPublic  *int              `json:"nPublic"`
+	IC       []json.RawMessage `json:"IC"`
+}
+
+// checkVerificationKey validates that the key is a Groth16 key over BN254
+// curve, and it has the expected amount of public inputs. The amount is not
+// checked when signals is negative.
+func checkVerificationKey(raw []byte, signals int) error {
+	var meta keyMetadata
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidVerificationKey, err)
+	}
+
+	if meta.Protocol != keyProtocol {
+		return fmt.Errorf("%w: protocol must be %s, got %q", ErrInvalidVerificationKey, keyProtocol, meta.Protocol)
+	}
+	if meta.Curve != keyCurve {
+		return fmt.Errorf("%w: curve must be %s, got %q", ErrInvalidVerificationKey, keyCurve, meta.Curve)
+	}
+	if meta.NPublic == nil {
+		return fmt.Errorf("%w: nPublic is missing", ErrInvalidVerificationKey)
+	}
+	if *meta.NPublic+1 != len(meta.IC) {
+		return fmt.Errorf("%w: nPublic is %d, but IC has %d points", ErrInvalidVerificationKey, *meta.NPublic, len(meta.IC))
+	}
+	if signals >= 0 && *meta.NPublic != signals {
+		return fmt.Errorf("%w: nPublic is %d, but signal layout has %d signals", ErrInvalidVerificationKey, *meta.NPublic, signals)
+	}
+
+	return nil
+}
+
+// checkKeyHash compares SHA-256 digest of the key with the hex-encoded one,
+// empty hash is not checked
+func checkKeyHash(raw []byte, hash string) error {
+	if hash == "" {
+		return nil
+	}
+
+	if b, err := hex.DecodeString(hash); err != nil || len(b) != sha256.Size {
+		return fmt.Errorf("invalid verification key hash %q: must be hex-encoded SHA-256 digest", hash)
+	}
+
+	sum := sha256.Sum256(raw)
+	if actual := hex.EncodeToString(sum[:]); !strings.EqualFold(actual, hash) {
+		return fmt.Errorf("%w: expected %s, got %s", ErrVerificationKeyHashMismatch, strings.ToLower(hash), actual)
+	}
+
+	return nil
+}
+
+// parseVerificationKey checks the key against the options and parses it with
+// the backend
+func parseVerificationKey(raw []byte, opts VerifyOptions) (Groth16Key, error) {
+	if err := checkKeyHash(raw, opts.verificationKeyHash); err != nil {
+		return nil, err
+	}
+
+	if err := checkVerificationKey(raw, opts.layout.Count); err != nil {
+		return nil, err
+	}
+
+	return opts.groth16Backend.ParseKey(raw)
+}
@@ -0,0 +1,81 @@
+package zkverifier_kit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPassportVerifier_KeyChecks(t *testing.T) {
+	sum := sha256.Sum256(verificationKey)
+	keyHash := hex.EncodeToString(sum[:])
+
+	testCases := []struct {
+		name string
+		key  []byte
+		opts []VerifyOption
+		want error
+	}{
+		{
+			name: "Valid key",
+			key:  verificationKey,
+		},
+		{
+			name: "Wrong protocol",
+			key:  modifyKey(t, func(key map[string]any) { key["protocol"] = "plonk" }),
+			want: ErrInvalidVerificationKey,
+		},
+		{
+			name: "Wrong curve",
+			key:  modifyKey(t, func(key map[string]any) { key["curve"] = "bls12381" }),
+			want: ErrInvalidVerificationKey,
+		},
+		{
+			name: "Missing nPublic",
+			key:  modifyKey(t, func(key map[string]any) { delete(key, "nPublic") }),
+			want: ErrInvalidVerificationKey,
+		},
+		{
+			name: "nPublic does not match IC",
+			key:  modifyKey(t, func(key map[string]any) { key["nPublic"] = 21 }),
+			want: ErrInvalidVerificationKey,
+		},
+		{
+			name: "nPublic does not match layout",
+			key:  verificationKey,
+			opts: []VerifyOption{WithSignalLayout(SignalLayout{Count: 23, Signals: DefaultSignalLayout().Signals})},
+			want: ErrInvalidVerificationKey,
+		},
+		{
+			name: "Pinned hash",
+			key:  verificationKey,
+			opts: []VerifyOption{WithVerificationKeyHash(strings.ToUpper(keyHash))},
+		},
+		{
+			name: "Pinned hash mismatch",
+			key:  mismatchedKey(t, 1),
+			opts: []VerifyOption{WithVerificationKeyHash(keyHash)},
+			want: ErrVerificationKeyHashMismatch,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewPassportVerifier(tc.key, tc.opts...)
+			if tc.want == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tc.want)
+		})
+	}
+
+	_, err := NewPassportVerifier(verificationKey, WithVerificationKeyHash("abc"))
+	assert.ErrorContains(t, err, "invalid verification key hash")
+
+	registry := NewKeyRegistry()
+	assert.ErrorIs(t, registry.Register("v1", modifyKey(t, func(key map[string]any) { key["curve"] = "bls12381" })), ErrInvalidVerificationKey)
+}
@@ -0,0 +1,126 @@
+package zkverifier_kit
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrKeyFileNotSet         = errors.New("verification key file is not set")
+	ErrInvalidReloadInterval = errors.New("key reload interval must be positive")
+)
+
+// loadedKey is the parsed verification key with the digest of its content
+type loadedKey struct {
+	key  Groth16Key
+	hash [sha256.Size]byte
+}
+
+// keyHolder stores the verification key, which can be swapped on reload while
+// the proofs are being verified
+type keyHolder struct {
+	current atomic.Pointer[loadedKey]
+	// mu serializes reloads
+	mu sync.Mutex
+}
+
+func newKeyHolder(raw []byte, opts VerifyOptions) (*keyHolder, error) {
+	key, err := parseVerificationKey(raw, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var h keyHolder
+	h.current.Store(&loadedKey{key: key, hash: sha256.Sum256(raw)})
+	return &h, nil
+}
+
+func (h *keyHolder) key() Groth16Key {
+	return h.current.Load().key
+}
+
+// ReloadVerificationKey reads the file from WithVerificationKeyFile again and
+// swaps the key if the content has changed. The new key is swapped in only
+// after it passes the same checks as in NewPassportVerifier, otherwise the
+// previous one stays in use.
+// The proofs that are being verified keep using the key they started with.
+// It reports whether the key was swapped.
+func (v *Verifier) ReloadVerificationKey() (bool, error) {
+	file := v.opts.verificationKeyFile
+	if file == "" || v.verificationKey == nil {
+		return false, ErrKeyFileNotSet
+	}
+
+	h := v.verificationKey
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return false, fmt.Errorf("failed to read verification key from file %q: %w", file, err)
+	}
+
+	hash := sha256.Sum256(raw)
+	if hash == h.current.Load().hash {
+		return false, nil
+	}
+
+	key, err := parseVerificationKey(raw, v.opts)
+	if err != nil {
+		return false, fmt.Errorf("failed to reload verification key from file %q: %w", file, err)
+	}
+
+	h.current.Store(&loadedKey{key: key, hash: hash})
+	return true, nil
+}
+
+// WatchVerificationKey polls the file from WithVerificationKeyFile every
+// interval and reloads the key on change, see ReloadVerificationKey. It blocks
+// until ctx is done, so run it in a separate goroutine. The optional onReload
+// is called with nil after each swap and with the error after each failure,
+// except the repeated ones, so that the same broken file is reported once.
+// A non-positive interval is rejected with ErrInvalidReloadInterval.
+func (v *Verifier) WatchVerificationKey(ctx context.Context, interval time.Duration, onReload func(error)) error {
+	if v.opts.verificationKeyFile == "" || v.verificationKey == nil {
+		return ErrKeyFileNotSet
+	}
+	if interval <= 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidReloadInterval, interval)
+	}
+
+	if onReload == nil {
+		onReload = func(error) {}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastErr string
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		swapped, err := v.ReloadVerificationKey()
+		switch {
+		case err != nil:
+			if err.Error() != lastErr {
+				onReload(err)
+			}
+			lastErr = err.Error()
+		case swapped:
+			lastErr = ""
+			onReload(nil)
+		default:
+			lastErr = ""
+		}
+	}
+}
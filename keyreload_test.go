@@ -0,0 +1,107 @@
+package zkverifier_kit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rarimo/zkverifier-kit/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifier_ReloadVerificationKey(t *testing.T) {
+	rawKey, proof := testutil.Groth16Proof(validProof.PubSignals)
+	file := filepath.Join(t.TempDir(), "key.json")
+	require.NoError(t, os.WriteFile(file, mismatchedKey(t, 1), 0o600))
+
+	verifier, err := NewPassportVerifier(nil,
+		WithNow(proofDate),
+		WithProofSelectorValue(validSelector),
+		WithVerificationKeyFile(file),
+	)
+	require.NoError(t, err)
+	assert.True(t, errors.Is(verifier.VerifyProof(proof), CodeGroth16Failed))
+
+	require.NoError(t, os.WriteFile(file, rawKey, 0o600))
+	swapped, err := verifier.ReloadVerificationKey()
+	require.NoError(t, err)
+	assert.True(t, swapped)
+	assert.NoError(t, verifier.VerifyProof(proof))
+
+	swapped, err = verifier.ReloadVerificationKey()
+	require.NoError(t, err)
+	assert.False(t, swapped)
+
+	// the previous key stays in use
+	require.NoError(t, os.WriteFile(file, []byte(`{"IC":[]}`), 0o600))
+	swapped, err = verifier.ReloadVerificationKey()
+	assert.ErrorIs(t, err, ErrInvalidVerificationKey)
+	assert.False(t, swapped)
+	assert.NoError(t, verifier.VerifyProof(proof))
+
+	verifier, err = NewPassportVerifier(rawKey)
+	require.NoError(t, err)
+	_, err = verifier.ReloadVerificationKey()
+	assert.ErrorIs(t, err, ErrKeyFileNotSet)
+}
+
+func TestVerifier_WatchVerificationKey(t *testing.T) {
+	rawKey, proof := testutil.Groth16Proof(validProof.PubSignals)
+	file := filepath.Join(t.TempDir(), "key.json")
+	require.NoError(t, os.WriteFile(file, mismatchedKey(t, 1), 0o600))
+
+	verifier, err := NewPassportVerifier(nil,
+		WithNow(proofDate),
+		WithProofSelectorValue(validSelector),
+		WithVerificationKeyFile(file),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reloads := make(chan error, 10)
+	done := make(chan error)
+	go func() {
+		done <- verifier.WatchVerificationKey(ctx, 10*time.Millisecond, func(err error) { reloads <- err })
+	}()
+
+	replaceFile(t, file, rawKey)
+	assert.NoError(t, <-reloads)
+	assert.NoError(t, verifier.VerifyProof(proof))
+
+	replaceFile(t, file, []byte("{"))
+	assert.ErrorIs(t, <-reloads, ErrInvalidVerificationKey)
+
+	// the same failure is reported once
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, reloads)
+	assert.NoError(t, verifier.VerifyProof(proof))
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestVerifier_WatchVerificationKey_Interval(t *testing.T) {
+	rawKey, _ := testutil.Groth16Proof(validProof.PubSignals)
+	file := filepath.Join(t.TempDir(), "key.json")
+	require.NoError(t, os.WriteFile(file, rawKey, 0o600))
+
+	verifier, err := NewPassportVerifier(nil, WithVerificationKeyFile(file))
+	require.NoError(t, err)
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		err = verifier.WatchVerificationKey(context.Background(), interval, nil)
+		assert.ErrorIs(t, err, ErrInvalidReloadInterval, interval)
+	}
+}
+
+// replaceFile writes the file atomically, so that the watcher never reads it
+// partially written
+func replaceFile(t *testing.T, name string, data []byte) {
+	tmp := name + ".tmp"
+	require.NoError(t, os.WriteFile(tmp, data, 0o600))
+	require.NoError(t, os.Rename(tmp, name))
+}
@@ -0,0 +1,135 @@
+package zkverifier_kit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	zkptypes "github.com/iden3/go-rapidsnark/types"
+)
+
+var ErrUnknownKeyVersion = errors.New("unknown verification key version")
+
+// KeyRegistry maps circuit versions to their verification keys. It allows a
+// single Verifier to accept proofs of several circuit generations, e.g. during
+// the migration window. The key for a proof is picked in the following order:
+//  1. the version from WithKeyVersion option;
+//  2. the version registered for the proof selector;
+//  3. each registered key in order of registration, until the proof is verified.
+//
+// Keys can be registered while the registry is in use.
+type KeyRegistry struct {
+	mu        sync.RWMutex
+	backend   Groth16Backend
+	keys      map[string]Groth16Key
+	versions  []string
+	selectors map[string]string
+}
+
+// NewKeyRegistry creates a registry, which parses the keys with
+// NewRapidsnarkBackend
+func NewKeyRegistry() *KeyRegistry {
+	return NewKeyRegistryWithBackend(NewRapidsnarkBackend())
+}
+
+// NewKeyRegistryWithBackend creates a registry, which parses the keys with the
+// provided backend
+func NewKeyRegistryWithBackend(backend Groth16Backend) *KeyRegistry {
+	return &KeyRegistry{
+		backend:   backend,
+		keys:      make(map[string]Groth16Key),
+		selectors: make(map[string]string),
+	}
+}
+
+// Register adds the key of the circuit version. The selectors are optional
+// decimal selector values, which are produced only by this circuit version.
+// Registering the same version again replaces its key. The key is parsed and
+// checked here, so a malformed key is rejected with ErrInvalidVerificationKey.
+// Since the versions may have different signal layouts, the amount of public
+// inputs is only checked to match IC length.
+func (r *KeyRegistry) Register(version string, rawKey []byte, selectors ...string) error {
+	if len(rawKey) == 0 {
+		return fmt.Errorf("version %q: %w", version, ErrVerificationKeyRequired)
+	}
+
+	if err := checkVerificationKey(rawKey, -1); err != nil {
+		return fmt.Errorf("version %q: %w", version, err)
+	}
+
+	key, err := r.backend.ParseKey(rawKey)
+	if err != nil {
+		return fmt.Errorf("version %q: %w", version, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, s := range selectors {
+		if v, ok := r.selectors[s]; ok && v != version {
+			return fmt.Errorf("selector %s is already registered for version %q", s, v)
+		}
+	}
+
+	if _, ok := r.keys[version]; !ok {
+		r.versions = append(r.versions, version)
+	}
+	r.keys[version] = key
+	for _, s := range selectors {
+		r.selectors[s] = version
+	}
+
+	return nil
+}
+
+// RegisterFile reads the key from file and registers it, see Register
+func (r *KeyRegistry) RegisterFile(version, name string, selectors ...string) error {
+	key, err := os.ReadFile(name)
+	if err != nil {
+		return fmt.Errorf("failed to read verification key from file %q: %w", name, err)
+	}
+
+	return r.Register(version, key, selectors...)
+}
+
+// Versions returns the registered versions in order of registration
+func (r *KeyRegistry) Versions() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]string(nil), r.versions...)
+}
+
+// verifyGroth16 verifies the proof with the key picked as described in
+// KeyRegistry. The selector is the Selector signal of the proof.
+func (r *KeyRegistry) verifyGroth16(proof zkptypes.ZKProof, version, selector string) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if version == "" {
+		version = r.selectors[selector]
+	}
+
+	if version != "" {
+		key, ok := r.keys[version]
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrUnknownKeyVersion, version)
+		}
+		return key.Verify(proof)
+	}
+
+	if len(r.versions) == 0 {
+		return ErrVerificationKeyRequired
+	}
+
+	var errs []error
+	for _, v := range r.versions {
+		err := r.keys[v].Verify(proof)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("version %q: %w", v, err))
+	}
+
+	return errors.Join(errs...)
+}
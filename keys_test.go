@@ -0,0 +1,83 @@
+package zkverifier_kit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyRegistry(t *testing.T) {
+	// groth16 verification always fails with these keys, so the picked key
+	// versions can be seen in errors
+	var (
+		oldKey = mismatchedKey(t, 1)
+		newKey = mismatchedKey(t, 2)
+	)
+
+	registry := NewKeyRegistry()
+	require.NoError(t, registry.Register("v1", oldKey))
+	require.NoError(t, registry.Register("v2", newKey, "1"))
+	assert.Error(t, registry.Register("v3", newKey, "1"))
+	assert.ErrorIs(t, registry.Register("v3", nil), ErrVerificationKeyRequired)
+	assert.Equal(t, []string{"v1", "v2"}, registry.Versions())
+
+	verifier, err := NewPassportVerifier(nil,
+		WithNow(proofDate),
+		WithProofSelectorValue(validSelector),
+		WithKeyRegistry(registry),
+	)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		version string
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "Trial verification",
+			want: []string{`version "v1"`, `version "v2"`},
+		},
+		{
+			name:    "Version hint",
+			version: "v2",
+			want:    []string{"groth16 verification failed"},
+			notWant: []string{`version "v1"`},
+		},
+		{
+			name:    "Unknown version",
+			version: "v0",
+			want:    []string{ErrUnknownKeyVersion.Error()},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := verifier.VerifyProof(validProof, WithKeyVersion(tc.version))
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, CodeGroth16Failed), err)
+			for _, want := range tc.want {
+				assert.ErrorContains(t, err, want)
+			}
+			for _, notWant := range tc.notWant {
+				assert.NotContains(t, err.Error(), notWant)
+			}
+		})
+	}
+}
+
+func TestKeyRegistry_Selector(t *testing.T) {
+	registry := NewKeyRegistry()
+	require.NoError(t, registry.Register("v1", mismatchedKey(t, 1)))
+	require.NoError(t, registry.Register("v2", mismatchedKey(t, 2), validSelector))
+
+	verifier, err := NewPassportVerifier(nil, WithNow(proofDate), WithProofSelectorValue(validSelector), WithKeyRegistry(registry))
+	require.NoError(t, err)
+
+	// only the key of v2 is tried, because it is registered for the proof selector
+	err = verifier.VerifyProof(validProof)
+	assert.True(t, errors.Is(err, CodeGroth16Failed), err)
+	assert.NotContains(t, err.Error(), "version")
+}
@@ -0,0 +1,154 @@
+package zkverifier_kit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// signalNames are used for PubSignal text representation in SignalLayout
+// configs. They match the keys of validation errors without the prefix.
+var signalNames = map[PubSignal]string{
+	Nullifier:                 "nullifier",
+	BirthDate:                 "birth_date",
+	ExpirationDate:            "expiration_date",
+	Citizenship:               "citizenship",
+	EventID:                   "event_id",
+	EventData:                 "event_data",
+	IdStateRoot:               "id_state_root",
+	Selector:                  "selector",
+	TimestampUpperBound:       "timestamp_upper_bound",
+	IdentityCounterUpperBound: "identity_counter_upper_bound",
+	BirthdateLowerBound:       "birth_date_lower_bound",
+	BirthdateUpperBound:       "birth_date_upper_bound",
+	ExpirationDateLowerBound:  "expiration_date_lower_bound",
+}
+
+// optionalSignals may be omitted in SignalLayout, so that the layouts of the
+// circuits without the birth date lower bound stay valid. The omitted signals
+// are empty strings.
+var optionalSignals = map[PubSignal]bool{
+	BirthdateLowerBound: true,
+}
+
+var ErrInvalidSignalLayout = errors.New("invalid signal layout")
+
+// SignalLayout describes the positions of named public signals in the proof and
+// the total count of signals, which depends on the circuit. The default layout
+// matches predefined PubSignal values, other layouts can be loaded from JSON or
+// YAML config:
+//
+//	count: 22
+//	signals:
+//	  nullifier: 0
+//	  birth_date: 1
+//	  ...
+type SignalLayout struct {
+	Count   int               `json:"count" yaml:"count"`
+	Signals map[PubSignal]int `json:"signals" yaml:"signals"`
+}
+
+// DefaultSignalLayout returns the layout of the current passport query circuit
+func DefaultSignalLayout() SignalLayout {
+	signals := make(map[PubSignal]int, len(signalNames))
+	for s := range signalNames {
+		signals[s] = int(s)
+	}
+
+	return SignalLayout{Count: 22, Signals: signals}
+}
+
+// ParseSignalLayout decodes the layout from JSON or YAML and validates it
+func ParseSignalLayout(raw []byte) (SignalLayout, error) {
+	var layout SignalLayout
+	if err := yaml.Unmarshal(raw, &layout); err != nil {
+		return layout, fmt.Errorf("failed to unmarshal signal layout: %w", err)
+	}
+
+	return layout, layout.Validate()
+}
+
+// ReadSignalLayoutFile reads the file and parses the layout with ParseSignalLayout
+func ReadSignalLayoutFile(name string) (SignalLayout, error) {
+	raw, err := os.ReadFile(name)
+	if err != nil {
+		return SignalLayout{}, fmt.Errorf("failed to read signal layout from file %q: %w", name, err)
+	}
+
+	return ParseSignalLayout(raw)
+}
+
+// Validate checks that every known signal has a unique index within the
+// signals count
+func (l SignalLayout) Validate() error {
+	if l.Count <= 0 {
+		return fmt.Errorf("%w: count must be positive", ErrInvalidSignalLayout)
+	}
+
+	for s := range l.Signals {
+		if _, ok := signalNames[s]; !ok {
+			return fmt.Errorf("%w: unknown signal %s", ErrInvalidSignalLayout, s)
+		}
+	}
+
+	seen := make(map[int]PubSignal, len(l.Signals))
+	for s := range signalNames {
+		i, ok := l.Signals[s]
+		if !ok && optionalSignals[s] {
+			continue
+		}
+		if !ok {
+			return fmt.Errorf("%w: signal %s is missing", ErrInvalidSignalLayout, s)
+		}
+		if i < 0 || i >= l.Count {
+			return fmt.Errorf("%w: index %d of signal %s is out of range [0, %d)", ErrInvalidSignalLayout, i, s, l.Count)
+		}
+		if dup, ok := seen[i]; ok {
+			return fmt.Errorf("%w: signals %s and %s have the same index %d", ErrInvalidSignalLayout, dup, s, i)
+		}
+		seen[i] = s
+	}
+
+	return nil
+}
+
+// arrange puts the signals in order of predefined PubSignal values, so that the
+// signals can be accessed by these values regardless of the layout. The signals
+// length must be equal to the layout count.
+func (l SignalLayout) arrange(signals []string) []string {
+	arranged := make([]string, ExpirationDateLowerBound+1)
+	for s, i := range l.Signals {
+		arranged[s] = signals[i]
+	}
+
+	return arranged
+}
+
+func (s PubSignal) String() string {
+	if name, ok := signalNames[s]; ok {
+		return name
+	}
+	if s == NoSignal {
+		return "none"
+	}
+	return fmt.Sprintf("PubSignal(%d)", int(s))
+}
+
+func (s PubSignal) MarshalText() ([]byte, error) {
+	if _, ok := signalNames[s]; !ok && s != NoSignal {
+		return nil, fmt.Errorf("unknown public signal %d", int(s))
+	}
+	return []byte(s.String()), nil
+}
+
+func (s *PubSignal) UnmarshalText(text []byte) error {
+	for sig, name := range signalNames {
+		if name == string(text) {
+			*s = sig
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown public signal %q", text)
+}
@@ -0,0 +1,110 @@
+package zkverifier_kit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSignalLayout(t *testing.T) {
+	testCases := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{
+			name: "Valid JSON",
+			raw: `{"count": 13, "signals": {
+				"nullifier": 0, "birth_date": 1, "expiration_date": 2, "citizenship": 3,
+				"event_id": 4, "event_data": 5, "id_state_root": 6, "selector": 7,
+				"timestamp_upper_bound": 8, "identity_counter_upper_bound": 9,
+				"birth_date_upper_bound": 10, "expiration_date_lower_bound": 12}}`,
+		},
+		{
+			name: "Valid YAML",
+			raw: `
+count: 12
+signals:
+  nullifier: 11
+  birth_date: 10
+  expiration_date: 9
+  citizenship: 8
+  event_id: 7
+  event_data: 6
+  id_state_root: 5
+  selector: 4
+  timestamp_upper_bound: 3
+  identity_counter_upper_bound: 2
+  birth_date_upper_bound: 1
+  expiration_date_lower_bound: 0
+`,
+		},
+		{
+			name: "Unknown signal",
+			raw:  `{"count": 1, "signals": {"unknown": 0}}`,
+			want: `unknown public signal "unknown"`,
+		},
+		{
+			name: "Missing signal",
+			raw:  `{"count": 1, "signals": {"nullifier": 0}}`,
+			want: ErrInvalidSignalLayout.Error(),
+		},
+		{
+			name: "Zero count",
+			raw:  `{"signals": {}}`,
+			want: "count must be positive",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseSignalLayout([]byte(tc.raw))
+			if tc.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorContains(t, err, tc.want)
+		})
+	}
+}
+
+func TestSignalLayout_Validate(t *testing.T) {
+	assert.NoError(t, DefaultSignalLayout().Validate())
+
+	outOfRange := DefaultSignalLayout()
+	outOfRange.Count = 19
+	assert.ErrorIs(t, outOfRange.Validate(), ErrInvalidSignalLayout)
+
+	duplicate := DefaultSignalLayout()
+	duplicate.Signals[Selector] = int(Nullifier)
+	assert.ErrorIs(t, duplicate.Validate(), ErrInvalidSignalLayout)
+
+	_, err := NewPassportVerifier(verificationKey, WithSignalLayout(duplicate))
+	assert.ErrorIs(t, err, ErrInvalidSignalLayout)
+}
+
+func TestSignalLayout_arrange(t *testing.T) {
+	// move all signals to the beginning in reversed order
+	reversed := SignalLayout{Count: 12, Signals: make(map[PubSignal]int)}
+	for i, s := range []PubSignal{
+		ExpirationDateLowerBound, BirthdateUpperBound, IdentityCounterUpperBound, TimestampUpperBound,
+		Selector, IdStateRoot, EventData, EventID, Citizenship, ExpirationDate, BirthDate, Nullifier,
+	} {
+		reversed.Signals[s] = i
+	}
+	require.NoError(t, reversed.Validate())
+
+	signals := make([]string, reversed.Count)
+	for s, i := range reversed.Signals {
+		signals[i] = validProof.PubSignals[s]
+	}
+
+	arranged := reversed.arrange(signals)
+	for s := range reversed.Signals {
+		assert.Equal(t, validProof.PubSignals[s], arranged[s], s.String())
+	}
+	// optional signal is omitted
+	assert.Empty(t, arranged[BirthdateLowerBound])
+}
@@ -0,0 +1,42 @@
+package nullifier
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrNullifierUsed shows that the nullifier was already reserved for the event,
+// so the proof is a replay
+var ErrNullifierUsed = errors.New("nullifier is already used")
+
+// MemoryStore keeps reserved nullifiers in memory. It is suitable for tests and
+// single-instance services, which may lose the state on restart.
+type MemoryStore struct {
+	mu       sync.Mutex
+	reserved map[key]struct{}
+}
+
+type key struct {
+	eventID   string
+	nullifier string
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{reserved: make(map[key]struct{})}
+}
+
+// Reserve marks the nullifier as used for the event. If it was already used,
+// ErrNullifierUsed is returned.
+func (s *MemoryStore) Reserve(_ context.Context, eventID, nullifier string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := key{eventID: eventID, nullifier: nullifier}
+	if _, ok := s.reserved[k]; ok {
+		return ErrNullifierUsed
+	}
+
+	s.reserved[k] = struct{}{}
+	return nil
+}
@@ -0,0 +1,43 @@
+package nullifier
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore_Reserve(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		store = NewMemoryStore()
+	)
+
+	assert.NoError(t, store.Reserve(ctx, "1", "100"))
+	assert.ErrorIs(t, store.Reserve(ctx, "1", "100"), ErrNullifierUsed)
+	assert.NoError(t, store.Reserve(ctx, "2", "100"))
+	assert.NoError(t, store.Reserve(ctx, "1", "200"))
+}
+
+func TestMemoryStore_ReserveConcurrent(t *testing.T) {
+	var (
+		store    = NewMemoryStore()
+		reserved atomic.Int32
+		wg       sync.WaitGroup
+	)
+
+	for range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if store.Reserve(context.Background(), "1", "100") == nil {
+				reserved.Add(1)
+			}
+		}()
+	}
+
+	wg.Wait()
+	assert.Equal(t, int32(1), reserved.Load())
+}
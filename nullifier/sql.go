@@ -0,0 +1,68 @@
+package nullifier
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+)
+
+// tableName matches a plain or schema-qualified SQL identifier, because the
+// table name can't be passed as query parameter
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// SQLStore keeps reserved nullifiers in a database table with a primary key on
+// (event_id, nullifier), so the reservation is atomic across service instances.
+// The queries are compatible with PostgreSQL and SQLite.
+type SQLStore struct {
+	db    *sql.DB
+	table string
+}
+
+// NewSQLStore creates the store over the table, which can be created with
+// SQLStore.CreateTable or with your own migrations. The table name must be an
+// unquoted identifier, optionally prefixed with schema.
+func NewSQLStore(db *sql.DB, table string) (*SQLStore, error) {
+	if !tableName.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name %q", table)
+	}
+
+	return &SQLStore{db: db, table: table}, nil
+}
+
+// CreateTable creates the table for nullifiers if it does not exist
+func (s *SQLStore) CreateTable(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %s (
+			event_id TEXT NOT NULL,
+			nullifier TEXT NOT NULL,
+			PRIMARY KEY (event_id, nullifier)
+		)`, s.table,
+	))
+	if err != nil {
+		return fmt.Errorf("failed to create nullifiers table: %w", err)
+	}
+
+	return nil
+}
+
+// Reserve inserts the nullifier for the event. If the row already exists,
+// ErrNullifierUsed is returned.
+func (s *SQLStore) Reserve(ctx context.Context, eventID, nullifier string) error {
+	res, err := s.db.ExecContext(ctx, fmt.Sprintf(
+		`INSERT INTO %s (event_id, nullifier) VALUES ($1, $2) ON CONFLICT DO NOTHING`, s.table,
+	), eventID, nullifier)
+	if err != nil {
+		return fmt.Errorf("failed to insert nullifier: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return ErrNullifierUsed
+	}
+
+	return nil
+}
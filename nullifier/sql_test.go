@@ -0,0 +1,73 @@
+package nullifier
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLStore_Reserve(t *testing.T) {
+	query := regexp.QuoteMeta(`INSERT INTO nullifiers (event_id, nullifier) VALUES ($1, $2) ON CONFLICT DO NOTHING`)
+
+	testCases := []struct {
+		name   string
+		result func(*sqlmock.ExpectedExec)
+		want   error
+	}{
+		{
+			name:   "New nullifier",
+			result: func(e *sqlmock.ExpectedExec) { e.WillReturnResult(sqlmock.NewResult(0, 1)) },
+		},
+		{
+			name:   "Used nullifier",
+			result: func(e *sqlmock.ExpectedExec) { e.WillReturnResult(sqlmock.NewResult(0, 0)) },
+			want:   ErrNullifierUsed,
+		},
+		{
+			name:   "Database error",
+			result: func(e *sqlmock.ExpectedExec) { e.WillReturnError(errors.New("connection refused")) },
+			want:   errors.New("failed to insert nullifier: connection refused"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			tc.result(mock.ExpectExec(query).WithArgs("1", "100"))
+
+			store, err := NewSQLStore(db, "nullifiers")
+			require.NoError(t, err)
+
+			err = store.Reserve(context.Background(), "1", "100")
+			switch {
+			case tc.want == nil:
+				assert.NoError(t, err)
+			case errors.Is(tc.want, ErrNullifierUsed):
+				assert.ErrorIs(t, err, ErrNullifierUsed)
+			default:
+				assert.EqualError(t, err, tc.want.Error())
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestNewSQLStore(t *testing.T) {
+	for _, table := range []string{"nullifiers", "public.nullifiers", "_nullifiers_v2"} {
+		_, err := NewSQLStore(nil, table)
+		assert.NoError(t, err, table)
+	}
+
+	for _, table := range []string{"", "2nullifiers", "nullifiers; DROP TABLE users", `"nullifiers"`, "a.b.c"} {
+		_, err := NewSQLStore(nil, table)
+		assert.Error(t, err, table)
+	}
+}
@@ -0,0 +1,90 @@
+package zkverifier_kit
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	val "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Observer is notified about the outcome of each proof verification, e.g. to
+// export metrics, see WithObserver. It is called synchronously after the
+// verification, and concurrently in Verifier.VerifyProofs, so implementations
+// must be fast and safe for concurrent use.
+type Observer interface {
+	ObserveVerification(ctx context.Context, o Observation)
+}
+
+// ObserverFunc is an adapter to use ordinary functions as Observer
+type ObserverFunc func(ctx context.Context, o Observation)
+
+func (f ObserverFunc) ObserveVerification(ctx context.Context, o Observation) {
+	f(ctx, o)
+}
+
+// Observation describes a single proof verification
+type Observation struct {
+	// Claims are the decoded public signals, nil if the signals are malformed
+	Claims *PassportClaims
+	// Checks maps the checked fields, the same as keys of val.Errors, e.g.
+	// "pub_signals/citizenship", to the failure codes. The passed checks have
+	// empty code, the checks which were not run after a structural failure are
+	// missing.
+	Checks map[string]Code
+	// RootLatency is the duration of IdentityRootVerifier call, zero if it was
+	// not called
+	RootLatency time.Duration
+	// Groth16Latency is the duration of Groth16 verification, zero if the
+	// proof was rejected before it
+	Groth16Latency time.Duration
+	// Err is the result of verification, the same as returned to the caller
+	Err error
+}
+
+// Failed returns the sorted codes of failed checks
+func (o Observation) Failed() []Code {
+	var codes []Code
+	for _, code := range o.Checks {
+		if code != "" {
+			codes = append(codes, code)
+		}
+	}
+	slices.Sort(codes)
+	return codes
+}
+
+// check records the outcome of the checks, the nil observation is ignored, so
+// it is safe to call when there are no observers
+func (o *Observation) check(errs val.Errors) {
+	if o == nil {
+		return
+	}
+
+	for field, err := range errs {
+		var (
+			code Code
+			verr *VerificationError
+		)
+		if errors.As(err, &verr) {
+			code = verr.Code
+		}
+		o.Checks[field] = code
+	}
+}
+
+// observe notifies the observers about the finished verification
+func (v *Verifier) observe(ctx context.Context, o *Observation, signals []string) {
+	if o == nil {
+		return
+	}
+
+	if claims, err := v.ParsePubSignals(signals); err == nil {
+		o.Claims = &claims
+	}
+
+	for _, observer := range v.opts.observers {
+		observer.ObserveVerification(ctx, *o)
+	}
+}
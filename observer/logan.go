@@ -0,0 +1,48 @@
+package observer
+
+import (
+	"context"
+
+	kit "github.com/rarimo/zkverifier-kit"
+	"gitlab.com/distributed_lab/logan/v3"
+)
+
+// Logan logs each verification with the decoded claims, the failure codes and
+// the latencies as fields: valid proofs on debug level, invalid ones on info
+// level, and internal errors on error level.
+type Logan struct {
+	log *logan.Entry
+}
+
+func NewLogan(log *logan.Entry) *Logan {
+	return &Logan{log: log}
+}
+
+func (l *Logan) ObserveVerification(_ context.Context, o kit.Observation) {
+	fields := logan.F{
+		"result":          Result(o.Err),
+		"root_latency":    o.RootLatency,
+		"groth16_latency": o.Groth16Latency,
+	}
+	if codes := o.Failed(); len(codes) > 0 {
+		fields["failures"] = codes
+	}
+	if c := o.Claims; c != nil {
+		fields["nullifier"] = c.Nullifier
+		fields["event_id"] = c.EventID
+		fields["selector"] = c.Selector.String()
+		if c.Citizenship != nil {
+			fields["citizenship"] = *c.Citizenship
+		}
+	}
+
+	entry := l.log.WithFields(fields)
+	switch Result(o.Err) {
+	case ResultValid:
+		entry.Debug("proof verified")
+	case ResultInvalid:
+		entry.WithError(o.Err).Info("proof rejected")
+	default:
+		entry.WithError(o.Err).Error("failed to verify proof")
+	}
+}
@@ -0,0 +1,100 @@
+package observer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	val "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	kit "github.com/rarimo/zkverifier-kit"
+	"github.com/rarimo/zkverifier-kit/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gitlab.com/distributed_lab/logan/v3"
+)
+
+var (
+	valid = kit.Observation{
+		Checks:         map[string]kit.Code{"pub_signals/citizenship": "", "/proof": ""},
+		RootLatency:    2 * time.Millisecond,
+		Groth16Latency: 3 * time.Millisecond,
+	}
+	invalid = kit.Observation{
+		Checks: map[string]kit.Code{
+			"pub_signals/citizenship": kit.CodeCitizenshipNotAllowed,
+			"pub_signals/event_id":    kit.CodeEventIDMismatch,
+		},
+		RootLatency: time.Millisecond,
+		Err:         val.Errors{"pub_signals/citizenship": kit.CodeCitizenshipNotAllowed},
+	}
+	failed = kit.Observation{Err: identity.ErrContractCall}
+)
+
+func TestResult(t *testing.T) {
+	assert.Equal(t, ResultValid, Result(valid.Err))
+	assert.Equal(t, ResultInvalid, Result(invalid.Err))
+	assert.Equal(t, ResultError, Result(failed.Err))
+	assert.Equal(t, ResultError, Result(errors.New("internal")))
+}
+
+func TestPrometheus(t *testing.T) {
+	metrics := NewPrometheus("test")
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(metrics))
+
+	for _, o := range []kit.Observation{valid, valid, invalid, failed} {
+		metrics.ObserveVerification(context.Background(), o)
+	}
+
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP test_proof_verifications_total Total number of proof verifications by result: valid, invalid or error.
+# TYPE test_proof_verifications_total counter
+test_proof_verifications_total{result="error"} 1
+test_proof_verifications_total{result="invalid"} 1
+test_proof_verifications_total{result="valid"} 2
+# HELP test_proof_verification_failures_total Total number of failed proof checks by failure code.
+# TYPE test_proof_verification_failures_total counter
+test_proof_verification_failures_total{code="citizenship_not_allowed"} 1
+test_proof_verification_failures_total{code="event_id_mismatch"} 1
+`), "test_proof_verifications_total", "test_proof_verification_failures_total"))
+
+	// the latencies are observed only for the steps that were run
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	counts := make(map[string]uint64)
+	for _, f := range families {
+		if f.GetType() == dto.MetricType_HISTOGRAM {
+			counts[f.GetName()] = f.GetMetric()[0].GetHistogram().GetSampleCount()
+		}
+	}
+	assert.Equal(t, map[string]uint64{
+		"test_root_verification_duration_seconds":    3,
+		"test_groth16_verification_duration_seconds": 2,
+	}, counts)
+}
+
+func TestLogan(t *testing.T) {
+	var out bytes.Buffer
+	log := logan.New().Out(&out).Level(logan.DebugLevel)
+	observer := NewLogan(log)
+
+	observer.ObserveVerification(context.Background(), valid)
+	observer.ObserveVerification(context.Background(), invalid)
+	observer.ObserveVerification(context.Background(), failed)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[0], "proof verified")
+	assert.Contains(t, lines[0], "result=valid")
+	assert.Contains(t, lines[1], "proof rejected")
+	assert.Contains(t, lines[1], "failures=\"[citizenship_not_allowed event_id_mismatch]\"")
+	assert.Contains(t, lines[2], "failed to verify proof")
+	assert.Contains(t, lines[2], "level=error")
+}
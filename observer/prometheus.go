@@ -0,0 +1,105 @@
+// Package observer provides kit.Observer implementations: Prometheus metrics
+// and structured logging with logan.
+package observer
+
+import (
+	"context"
+	"errors"
+
+	val "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	kit "github.com/rarimo/zkverifier-kit"
+)
+
+// Result is the label of verification result
+const (
+	ResultValid   = "valid"
+	ResultInvalid = "invalid"
+	ResultError   = "error"
+)
+
+// Prometheus exports the counters of verifications and failure codes, and the
+// histograms of root verification and Groth16 latencies. Register it in your
+// registry and pass to kit.WithObserver:
+//
+//	metrics := observer.NewPrometheus("passport")
+//	prometheus.MustRegister(metrics)
+//	verifier, err := kit.NewPassportVerifier(key, kit.WithObserver(metrics))
+type Prometheus struct {
+	verifications  *prometheus.CounterVec
+	failures       *prometheus.CounterVec
+	rootLatency    prometheus.Histogram
+	groth16Latency prometheus.Histogram
+}
+
+// NewPrometheus creates the metrics with the namespace, e.g. the metric of
+// verifications is <namespace>_proof_verifications_total
+func NewPrometheus(namespace string) *Prometheus {
+	return &Prometheus{
+		verifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "proof_verifications_total",
+			Help:      "Total number of proof verifications by result: valid, invalid or error.",
+		}, []string{"result"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "proof_verification_failures_total",
+			Help:      "Total number of failed proof checks by failure code.",
+		}, []string{"code"}),
+		rootLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "root_verification_duration_seconds",
+			Help:      "Duration of identity root verification.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		groth16Latency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "groth16_verification_duration_seconds",
+			Help:      "Duration of Groth16 proof verification.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25},
+		}),
+	}
+}
+
+func (p *Prometheus) ObserveVerification(_ context.Context, o kit.Observation) {
+	p.verifications.WithLabelValues(Result(o.Err)).Inc()
+
+	for _, code := range o.Failed() {
+		p.failures.WithLabelValues(string(code)).Inc()
+	}
+
+	if o.RootLatency > 0 {
+		p.rootLatency.Observe(o.RootLatency.Seconds())
+	}
+	if o.Groth16Latency > 0 {
+		p.groth16Latency.Observe(o.Groth16Latency.Seconds())
+	}
+}
+
+func (p *Prometheus) Describe(ch chan<- *prometheus.Desc) {
+	p.verifications.Describe(ch)
+	p.failures.Describe(ch)
+	p.rootLatency.Describe(ch)
+	p.groth16Latency.Describe(ch)
+}
+
+func (p *Prometheus) Collect(ch chan<- prometheus.Metric) {
+	p.verifications.Collect(ch)
+	p.failures.Collect(ch)
+	p.rootLatency.Collect(ch)
+	p.groth16Latency.Collect(ch)
+}
+
+// Result classifies the verification error: nil is valid, val.Errors is
+// invalid, and the rest are internal errors, e.g. identity.ErrContractCall
+func Result(err error) string {
+	var errs val.Errors
+	switch {
+	case err == nil:
+		return ResultValid
+	case errors.As(err, &errs):
+		return ResultInvalid
+	default:
+		return ResultError
+	}
+}
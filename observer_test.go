@@ -0,0 +1,126 @@
+package zkverifier_kit
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	zkptypes "github.com/iden3/go-rapidsnark/types"
+	"github.com/rarimo/zkverifier-kit/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithObserver(t *testing.T) {
+	key, proof := testutil.Groth16Proof(validProof.PubSignals)
+
+	var (
+		mu           sync.Mutex
+		observations []Observation
+	)
+	observer := ObserverFunc(func(_ context.Context, o Observation) {
+		mu.Lock()
+		defer mu.Unlock()
+		observations = append(observations, o)
+	})
+
+	verifier, err := NewPassportVerifier(key,
+		WithNow(proofDate),
+		WithProofSelectorValue(validSelector),
+		WithAgeAbove(equalAge),
+		WithObserver(observer),
+	)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		proof   func() ([]string, []VerifyOption)
+		checks  map[string]Code
+		missing []string
+		groth16 bool
+	}{
+		{
+			name: "Valid proof",
+			checks: map[string]Code{
+				"pub_signals/selector":    "",
+				"pub_signals/citizenship": "",
+				"pub_signals/birth_date":  "",
+				"/proof":                  "",
+			},
+			groth16: true,
+		},
+		{
+			name: "Failed checks",
+			proof: func() ([]string, []VerifyOption) {
+				return proof.PubSignals, []VerifyOption{WithCitizenships("USA"), WithEventID(invalidEventID)}
+			},
+			checks: map[string]Code{
+				"pub_signals/citizenship": CodeCitizenshipNotAllowed,
+				"pub_signals/event_id":    CodeEventIDMismatch,
+				"pub_signals/nullifier":   "",
+			},
+			missing: []string{"/proof"},
+		},
+		{
+			name: "Structural failure",
+			proof: func() ([]string, []VerifyOption) {
+				return proof.PubSignals, []VerifyOption{WithProofSelectorValue("1")}
+			},
+			checks:  map[string]Code{"pub_signals/selector": CodeSelectorMismatch},
+			missing: []string{"pub_signals/nullifier", "/proof"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			observations = nil
+
+			p, opts := proof, []VerifyOption(nil)
+			if tc.proof != nil {
+				p.PubSignals, opts = tc.proof()
+			}
+			err := verifier.VerifyProof(p, opts...)
+
+			require.Len(t, observations, 1)
+			o := observations[0]
+			assert.Equal(t, err, o.Err)
+			require.NotNil(t, o.Claims)
+			assert.Equal(t, p.PubSignals[Nullifier], o.Claims.Nullifier.String())
+
+			for field, code := range tc.checks {
+				actual, ok := o.Checks[field]
+				require.True(t, ok, field)
+				assert.Equal(t, code, actual, field)
+			}
+			for _, field := range tc.missing {
+				assert.NotContains(t, o.Checks, field)
+			}
+			assert.Equal(t, tc.groth16, o.Groth16Latency > 0)
+		})
+	}
+
+	t.Run("Observer passed to VerifyProof is added", func(t *testing.T) {
+		observations = nil
+		var called bool
+		require.NoError(t, verifier.VerifyProof(proof, WithObserver(ObserverFunc(func(context.Context, Observation) {
+			called = true
+		}))))
+		assert.True(t, called)
+		assert.Len(t, observations, 1)
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		observations = nil
+		verifier.VerifyProofs(context.Background(), []zkptypes.ZKProof{proof, proof, proof})
+		assert.Len(t, observations, 3)
+	})
+}
+
+func TestObservation_Failed(t *testing.T) {
+	o := Observation{Checks: map[string]Code{
+		"pub_signals/selector":    "",
+		"pub_signals/event_id":    CodeEventIDMismatch,
+		"pub_signals/citizenship": CodeCitizenshipNotAllowed,
+	}}
+	assert.Equal(t, []Code{CodeCitizenshipNotAllowed, CodeEventIDMismatch}, o.Failed())
+}
@@ -1,10 +1,15 @@
 package zkverifier_kit
 
 import (
+	"context"
+	"errors"
+	"runtime"
+	"slices"
 	"time"
 
 	val "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/rarimo/zkverifier-kit/identity"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // VerifyOptions structure that stores all fields that may be validated before proof verification.
@@ -14,10 +19,21 @@ import (
 type VerifyOptions struct {
 	// age - a minimal age required to proof some statement.
 	age int
+	// maxAge - the age, which the holder must not have reached yet
+	maxAge int
+	// birthDateBefore - the holder must be born strictly before this date
+	birthDateBefore time.Time
+	// birthDateAfter - the holder must be born strictly after this date
+	birthDateAfter time.Time
 	// citizenships - array of interfaces (for more convenient usage during validation) that stores
 	// all citizenships that accepted in proof. Under the hood, it is a string of Alpha-3 county codes,
 	// described in the ISO 3166 international standard.
 	citizenships []interface{}
+	// excludedCitizenships - Alpha-3 country codes, which are rejected in proof
+	excludedCitizenships []interface{}
+	// citizenshipsErr, excludedCitizenshipsErr - unknown country codes in the
+	// citizenship options, reported on Verifier creation
+	citizenshipsErr, excludedCitizenshipsErr error
 	// eventDataRule - validation rule for EventData, where it's either an address or a string
 	eventDataRule val.Rule
 	// eventID - unique identifier associated with a specific event or interaction within
@@ -28,16 +44,49 @@ type VerifyOptions struct {
 	rootVerifier IdentityRootVerifier
 	// verificationKeyFile - stores verification key for proofs
 	verificationKeyFile string
+	// verificationKeyHash - hex-encoded SHA-256 digest, which the verification key must have
+	verificationKeyHash string
 	// maxIdentitiesCount - maximum amount of reissued identities that user can have
 	maxIdentitiesCount int64
 	// maxIdentityCreationTimestamp - the upper bound of timestamp when user could create identities
 	maxIdentityCreationTimestamp time.Time
 	// proofSelectorValue - bit mask for selecting fields for verification
 	proofSelectorValue string
+	// layout - positions of public signals in the proof
+	layout SignalLayout
+	// keyRegistry - verification keys of several circuit versions
+	keyRegistry *KeyRegistry
+	// keyVersion - circuit version hint to pick the key from keyRegistry
+	keyVersion string
+	// nullifierStore - storage of used nullifiers to reject replayed proofs
+	nullifierStore NullifierStore
+	// batchWorkers - maximum amount of proofs verified in parallel by Verifier.VerifyProofs
+	batchWorkers int
+	// clock - source of the current time for all date-based checks
+	clock func() time.Time
+	// groth16Backend - implementation of Groth16 verification
+	groth16Backend Groth16Backend
+	// observers - receivers of verification outcomes
+	observers []Observer
+	// tracer - OpenTelemetry tracer of verification spans
+	tracer trace.Tracer
 }
 
+// IdentityRootVerifier checks IdStateRoot signal. The context is passed from
+// Verifier.VerifyProofContext, so implementations should respect its
+// cancellation.
 type IdentityRootVerifier interface {
 	VerifyRoot(root string) error
+	VerifyRootContext(ctx context.Context, root string) error
+}
+
+// NullifierStore keeps the nullifiers, which were already used for events. See
+// nullifier package for implementations.
+type NullifierStore interface {
+	// Reserve atomically checks that the nullifier was not used for the event
+	// and marks it as used. It must return nullifier.ErrNullifierUsed if the
+	// nullifier was already used.
+	Reserve(ctx context.Context, eventID, nullifier string) error
 }
 
 // VerifyOption type alias for function that may add new values to VerifyOptions structure.
@@ -53,15 +102,65 @@ func WithAgeAbove(age int) VerifyOption {
 	}
 }
 
+// WithAgeBelow takes the age, which the passport holder must not have reached
+// yet, e.g. 26 for the holders of 25 years and younger. Like WithAgeAbove, it is
+// checked either against BirthDate signal or against BirthdateLowerBound,
+// which must be equal to the earliest allowed birth date: the current date
+// minus the age plus one day.
+func WithAgeBelow(age int) VerifyOption {
+	return func(opts *VerifyOptions) {
+		opts.maxAge = age
+	}
+}
+
+// WithAgeBetween takes the inclusive range of holder ages, e.g. 18 and 25. It
+// is the same as WithAgeAbove(min) with WithAgeBelow(max+1).
+func WithAgeBetween(min, max int) VerifyOption {
+	return func(opts *VerifyOptions) {
+		opts.age = min
+		opts.maxAge = max + 1
+	}
+}
+
+// WithBirthDateBefore requires the holder to be born strictly before the date.
+// The proof must reveal either BirthDate signal or BirthdateUpperBound equal to
+// the day before the date. When combined with WithAgeAbove, the earlier limit
+// is used.
+func WithBirthDateBefore(date time.Time) VerifyOption {
+	return func(opts *VerifyOptions) {
+		opts.birthDateBefore = date
+	}
+}
+
+// WithBirthDateAfter requires the holder to be born strictly after the date.
+// The proof must reveal either BirthDate signal or BirthdateLowerBound equal to
+// the day after the date. When combined with WithAgeBelow, the later limit is
+// used.
+func WithBirthDateAfter(date time.Time) VerifyOption {
+	return func(opts *VerifyOptions) {
+		opts.birthDateAfter = date
+	}
+}
+
 // WithCitizenships adds new available citizenship/s to prove that user is a resident of specified country.
 // Function takes an arbitrary number of strings that consists from Alpha-3 county codes,
-// described in the ISO 3166 international standard (e.g. "USA", "UKR", "TUR").
+// described in the ISO 3166 international standard (e.g. "USA", "UKR", "TUR"), or the names
+// of country groups (e.g. "EU"), see RegisterCountryGroup. Alpha-2 and numeric codes are
+// normalized to Alpha-3, and unknown codes are rejected with ErrUnknownCountry by
+// NewPassportVerifier, see LookupCountry.
 func WithCitizenships(citizenships ...string) VerifyOption {
 	return func(opts *VerifyOptions) {
-		opts.citizenships = make([]interface{}, len(citizenships))
-		for i, ctz := range citizenships {
-			opts.citizenships[i] = ctz
-		}
+		opts.citizenships, opts.citizenshipsErr = expandCountries(citizenships)
+	}
+}
+
+// WithCitizenshipsExcluded takes the citizenships, which are rejected, while
+// all the others are allowed. The codes are the same as in WithCitizenships,
+// including the country groups. When both options are set, the citizenship
+// must be allowed and not excluded.
+func WithCitizenshipsExcluded(citizenships ...string) VerifyOption {
+	return func(opts *VerifyOptions) {
+		opts.excludedCitizenships, opts.excludedCitizenshipsErr = expandCountries(citizenships)
 	}
 }
 
@@ -87,6 +186,8 @@ func WithEventID(identifier string) VerifyOption {
 }
 
 // WithProofSelectorValue takes selector as a string that represents bit mask in a decimal format.
+// Without it, the proof may have any selector, and the options are still checked against the
+// fields it enables.
 func WithProofSelectorValue(selector string) VerifyOption {
 	return func(opts *VerifyOptions) {
 		opts.proofSelectorValue = selector
@@ -110,6 +211,16 @@ func WithVerificationKeyFile(name string) VerifyOption {
 	}
 }
 
+// WithVerificationKeyHash pins the verification key to the hex-encoded SHA-256
+// digest of its content, e.g. the output of sha256sum. NewPassportVerifier
+// fails with ErrVerificationKeyHashMismatch if the key differs, and so does the
+// key reload.
+func WithVerificationKeyHash(hash string) VerifyOption {
+	return func(opts *VerifyOptions) {
+		opts.verificationKeyHash = hash
+	}
+}
+
 // WithIdentitiesCounter takes maximum amount of identities that user can have
 // during proof verification.
 //
@@ -130,6 +241,83 @@ func WithIdentitiesCreationTimestampLimit(unixTime int64) VerifyOption {
 	}
 }
 
+// WithClock takes a source of the current time, which is used by every
+// date-based check (age, passport expiration). By default, time.Now is used.
+func WithClock(clock func() time.Time) VerifyOption {
+	return func(opts *VerifyOptions) {
+		opts.clock = clock
+	}
+}
+
+// WithNow fixes the current time for date-based checks. It is useful for
+// replaying archived proofs, which were generated on a specific date.
+func WithNow(now time.Time) VerifyOption {
+	return WithClock(func() time.Time { return now })
+}
+
+// WithSignalLayout takes the positions of public signals for the circuit, which
+// the proofs are generated with. By default, DefaultSignalLayout is used.
+func WithSignalLayout(layout SignalLayout) VerifyOption {
+	return func(opts *VerifyOptions) {
+		opts.layout = layout
+	}
+}
+
+// WithKeyRegistry makes Verifier pick the verification key for each proof from
+// the registry, see KeyRegistry. The verification key passed to
+// NewPassportVerifier is not required in this case, and it is ignored.
+func WithKeyRegistry(r *KeyRegistry) VerifyOption {
+	return func(opts *VerifyOptions) {
+		opts.keyRegistry = r
+	}
+}
+
+// WithKeyVersion takes a circuit version of the proof, which is used to pick the
+// key from KeyRegistry. It is usually passed to VerifyProof, when the client
+// reports its version.
+func WithKeyVersion(version string) VerifyOption {
+	return func(opts *VerifyOptions) {
+		opts.keyVersion = version
+	}
+}
+
+// WithNullifierStore makes VerifyProof reject proofs with nullifiers that were
+// already used for the same event. The nullifier is reserved only after all
+// the other checks pass, so invalid proofs don't consume it.
+func WithNullifierStore(store NullifierStore) VerifyOption {
+	return func(opts *VerifyOptions) {
+		opts.nullifierStore = store
+	}
+}
+
+// WithBatchWorkers limits the amount of proofs verified in parallel by
+// Verifier.VerifyProofs. By default, it is equal to GOMAXPROCS.
+func WithBatchWorkers(n int) VerifyOption {
+	return func(opts *VerifyOptions) {
+		opts.batchWorkers = n
+	}
+}
+
+// WithGroth16Backend sets the implementation of Groth16 verification, the
+// default is NewRapidsnarkBackend. The verification key is parsed by the backend
+// in NewPassportVerifier, so the option has no effect in VerifyProof. The keys
+// of KeyRegistry are parsed by its own backend, see NewKeyRegistryWithBackend.
+func WithGroth16Backend(backend Groth16Backend) VerifyOption {
+	return func(opts *VerifyOptions) {
+		opts.groth16Backend = backend
+	}
+}
+
+// WithObserver adds the observer, which is notified after each verification
+// in VerifyProof, VerifyProofContext and VerifyProofs. The option can be passed
+// several times, the observers passed to VerifyProof are added to the initial
+// ones.
+func WithObserver(observer Observer) VerifyOption {
+	return func(opts *VerifyOptions) {
+		opts.observers = append(slices.Clip(opts.observers), observer)
+	}
+}
+
 // mergeOptions collects all parameters together and fills VerifyOptions struct
 // with it, overwriting existing values
 func mergeOptions(withDefaults bool, opts VerifyOptions, options ...VerifyOption) VerifyOptions {
@@ -137,6 +325,11 @@ func mergeOptions(withDefaults bool, opts VerifyOptions, options ...VerifyOption
 		opts.maxIdentitiesCount = -1
 		opts.age = -1
 		opts.rootVerifier = identity.NewDisabledVerifier()
+		opts.clock = time.Now
+		opts.layout = DefaultSignalLayout()
+		opts.batchWorkers = runtime.GOMAXPROCS(0)
+		opts.groth16Backend = NewRapidsnarkBackend()
+		opts.tracer = noopTracer
 	}
 
 	for _, opt := range options {
@@ -145,3 +338,12 @@ func mergeOptions(withDefaults bool, opts VerifyOptions, options ...VerifyOption
 
 	return opts
 }
+
+// validate checks the options, which can't be checked on applying
+func (o VerifyOptions) validate() error {
+	if err := o.layout.Validate(); err != nil {
+		return err
+	}
+
+	return errors.Join(o.citizenshipsErr, o.excludedCitizenshipsErr)
+}
@@ -1,6 +1,7 @@
 package zkverifier_kit
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"maps"
@@ -10,14 +11,16 @@ import (
 
 	val "github.com/go-ozzo/ozzo-validation/v4"
 	zkptypes "github.com/iden3/go-rapidsnark/types"
-	zkpverifier "github.com/iden3/go-rapidsnark/verifier"
 	"github.com/rarimo/zkverifier-kit/identity"
+	"github.com/rarimo/zkverifier-kit/nullifier"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type PubSignal int
 
 // predefined values and positions for public inputs in zero knowledge proof. It
-// may change depending on the proof and the values that it reveals.
+// may change depending on the proof and the values that it reveals, in this case
+// provide the actual positions with WithSignalLayout.
 const (
 	Nullifier                 PubSignal = 0
 	BirthDate                 PubSignal = 1
@@ -29,6 +32,7 @@ const (
 	Selector                  PubSignal = 12
 	TimestampUpperBound       PubSignal = 14
 	IdentityCounterUpperBound PubSignal = 16
+	BirthdateLowerBound       PubSignal = 17
 	BirthdateUpperBound       PubSignal = 18
 	ExpirationDateLowerBound  PubSignal = 19
 )
@@ -38,35 +42,51 @@ var ErrVerificationKeyRequired = errors.New("verification key is required")
 // Verifier is a structure representing some instance for validation and verification zero knowledge proof
 // generated by Rarimo system.
 type Verifier struct {
-	// verificationKey stores verification key content
-	verificationKey []byte
+	// verificationKey is the parsed verification key, it is nil when the key
+	// registry is used
+	verificationKey *keyHolder
 	// opts has fields that must be validated before proof verification.
 	opts VerifyOptions
 }
 
 // NewPassportVerifier creates a new Verifier instance. VerificationKey is
-// required to VerifyGroth16, usually you should just read it from file. Optional
-// parameters will take part in proof verification on Verifier.VerifyProof call.
+// required to VerifyGroth16, usually you should just read it from file. The key
+// is parsed once here, so a malformed key is rejected with
+// ErrInvalidVerificationKey. It must be a Groth16 key over bn128 curve with the
+// amount of public inputs equal to the signal layout count. Optional parameters
+// will take part in proof verification on Verifier.VerifyProof call.
 //
-// If you provided WithVerificationKeyFile option, you can pass nil as the first arg.
+// If you provided WithVerificationKeyFile or WithKeyRegistry option, you can pass
+// nil as the first arg. The key file can be reloaded later with
+// Verifier.WatchVerificationKey.
 func NewPassportVerifier(verificationKey []byte, options ...VerifyOption) (*Verifier, error) {
 	verifier := Verifier{
-		verificationKey: verificationKey,
-		opts:            mergeOptions(true, VerifyOptions{}, options...),
+		opts: mergeOptions(true, VerifyOptions{}, options...),
 	}
 
-	file := verifier.opts.verificationKeyFile
-	if file == "" {
-		if len(verificationKey) == 0 {
+	if err := verifier.opts.validate(); err != nil {
+		return nil, err
+	}
+
+	if file := verifier.opts.verificationKeyFile; file != "" {
+		var err error
+		verificationKey, err = os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read verification key from file %q: %w", file, err)
+		}
+	}
+
+	if len(verificationKey) == 0 {
+		if verifier.opts.keyRegistry == nil {
 			return nil, ErrVerificationKeyRequired
 		}
 		return &verifier, nil
 	}
 
 	var err error
-	verifier.verificationKey, err = os.ReadFile(file)
+	verifier.verificationKey, err = newKeyHolder(verificationKey, verifier.opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read verification key from file %q: %w", file, err)
+		return nil, err
 	}
 
 	return &verifier, nil
@@ -78,110 +98,402 @@ func NewPassportVerifier(verificationKey []byte, options ...VerifyOption) (*Veri
 // values are required for different proofs, the options can be passed to
 // VerifyProof, which override the initial ones.
 func (v *Verifier) VerifyProof(proof zkptypes.ZKProof, options ...VerifyOption) error {
+	return v.VerifyProofContext(context.Background(), proof, options...)
+}
+
+// VerifyProofContext is the same as VerifyProof, but the context is passed to
+// IdentityRootVerifier, so the root verification can be canceled with it.
+func (v *Verifier) VerifyProofContext(ctx context.Context, proof zkptypes.ZKProof, options ...VerifyOption) error {
 	v2 := Verifier{
 		verificationKey: v.verificationKey,
 		opts:            mergeOptions(false, v.opts, options...),
 	}
 
-	if err := v2.validateBase(proof); err != nil {
+	if len(options) > 0 {
+		if err := v2.opts.validate(); err != nil {
+			return err
+		}
+	}
+
+	return v2.verify(ctx, proof)
+}
+
+// verify validates public signals and verifies the proof with the options that
+// are already merged
+func (v *Verifier) verify(ctx context.Context, proof zkptypes.ZKProof) (err error) {
+	ctx, span := v.startSpan(ctx, "zkverifier.VerifyProof")
+	defer func() { endSpan(span, err) }()
+
+	var obs *Observation
+	if len(v.opts.observers) > 0 {
+		obs = &Observation{Checks: make(map[string]Code)}
+	}
+
+	err = v.verifyObserved(ctx, proof, obs)
+	if obs != nil {
+		obs.Err = err
+		v.observe(ctx, obs, proof.PubSignals)
+	}
+
+	return err
+}
+
+// verifyObserved records the outcome of each step in obs, which can be nil
+func (v *Verifier) verifyObserved(ctx context.Context, proof zkptypes.ZKProof, obs *Observation) error {
+	if err := v.validateSignals(ctx, proof, obs); err != nil {
 		return err
 	}
 
-	if err := zkpverifier.VerifyGroth16(proof, v.verificationKey); err != nil {
-		return val.Errors{
-			"/proof": fmt.Errorf("groth16 verification failed: %w", err),
-		}
+	var attrs []attribute.KeyValue
+	if v.opts.keyRegistry != nil {
+		attrs = append(attrs, attribute.String("key_version", v.opts.keyVersion))
+	}
+	_, span := v.startSpan(ctx, "zkverifier.VerifyGroth16", attrs...)
+	start := time.Now()
+	err := v.verifyGroth16(proof)
+	if obs != nil {
+		obs.Groth16Latency = time.Since(start)
+	}
+	if err != nil {
+		err = verificationErr(
+			fmt.Errorf("groth16 verification failed: %w", err),
+			CodeGroth16Failed, NoSignal, nil, nil,
+		)
+	}
+	obs.check(val.Errors{"/proof": err})
+	if err != nil {
+		err = Errors{"/proof": err}
+	}
+	endSpan(span, err)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	err = v.reserveNullifier(ctx, proof.PubSignals)
+	var errs val.Errors
+	if errors.As(err, &errs) {
+		obs.check(errs)
+	}
+
+	return err
 }
 
-func (v *Verifier) validateBase(zkProof zkptypes.ZKProof) error {
-	signals := zkProof.PubSignals
+func (v *Verifier) verifyGroth16(proof zkptypes.ZKProof) error {
+	if v.opts.keyRegistry == nil {
+		return v.verificationKey.key().Verify(proof)
+	}
+
+	selector := v.opts.layout.arrange(proof.PubSignals)[Selector]
+	return v.opts.keyRegistry.verifyGroth16(proof, v.opts.keyVersion, selector)
+}
+
+// reserveNullifier is the last step of verification, because the nullifier
+// must not be consumed by an invalid proof
+func (v *Verifier) reserveNullifier(ctx context.Context, signals []string) error {
+	if v.opts.nullifierStore == nil {
+		return nil
+	}
 
-	err := val.Errors{
-		"zk_proof/proof":       val.Validate(zkProof.Proof, val.Required),
-		"zk_proof/pub_signals": val.Validate(signals, val.Required, val.Length(22, 22)),
-	}.Filter()
+	signals = v.opts.layout.arrange(signals)
+	err := v.opts.nullifierStore.Reserve(ctx, signals[EventID], signals[Nullifier])
+	if errors.Is(err, nullifier.ErrNullifierUsed) {
+		return Errors{"pub_signals/nullifier": verificationErr(err, CodeNullifierUsed, Nullifier, nil, signals[Nullifier])}
+	}
 	if err != nil {
+		return fmt.Errorf("failed to reserve nullifier: %w", err)
+	}
+
+	return nil
+}
+
+// validateSignals is validateBase in its own span
+func (v *Verifier) validateSignals(ctx context.Context, zkProof zkptypes.ZKProof, obs *Observation) (err error) {
+	ctx, span := v.startSpan(ctx, "zkverifier.ValidateSignals")
+	defer func() { endSpan(span, err) }()
+
+	return v.validateBase(ctx, zkProof, obs)
+}
+
+func (v *Verifier) validateBase(ctx context.Context, zkProof zkptypes.ZKProof, obs *Observation) error {
+	format := v.validateFormat(zkProof)
+	obs.check(format)
+	if err := newErrors(format); err != nil {
 		return err
 	}
 
-	err = v.opts.rootVerifier.VerifyRoot(signals[IdStateRoot])
+	signals := v.opts.layout.arrange(zkProof.PubSignals)
+
+	mask, err := v.validateSelector(signals)
+	obs.check(val.Errors{"pub_signals/selector": err})
+	if err != nil {
+		return Errors{"pub_signals/selector": err}
+	}
+
+	start := time.Now()
+	err = v.opts.rootVerifier.VerifyRootContext(ctx, signals[IdStateRoot])
+	if obs != nil {
+		obs.RootLatency = time.Since(start)
+	}
 	if errors.Is(err, identity.ErrContractCall) {
 		return err
 	}
 
 	all := val.Errors{
-		"pub_signals/nullifier":     val.Validate(signals[Nullifier], val.Required),
-		"pub_signals/selector":      val.Validate(signals[Selector], val.Required, val.In(v.opts.proofSelectorValue)),
-		"pub_signals/id_state_root": err,
-		"pub_signals/event_id":      validateOnOptSet(signals[EventID], v.opts.eventID, val.In(v.opts.eventID)),
-		// upper bound is a date: the earlier it is, the higher the age
-		"pub_signals/citizenship": validateOnOptSet(decodeInt(signals[Citizenship]), v.opts.citizenships, val.In(v.opts.citizenships...)),
-		"pub_signals/event_data":  validateOnOptSet(signals[EventData], v.opts.eventDataRule, v.opts.eventDataRule),
+		"pub_signals/nullifier":     v.validateNullifier(signals),
+		"pub_signals/id_state_root": verificationErr(err, CodeRootInvalid, IdStateRoot, nil, signals[IdStateRoot]),
+		"pub_signals/event_id":      v.validateEventID(signals),
+		"pub_signals/citizenship":   v.validateCitizenship(signals, mask),
+		"pub_signals/event_data":    v.validateEventData(signals),
 	}
 
-	maps.Copy(all, v.validateBirthDate(signals))
+	maps.Copy(all, v.validateBirthDate(signals, mask))
 	maps.Copy(all, v.validatePassportExpiration(signals))
-	maps.Copy(all, v.validateIdentitiesInputs(signals))
+	maps.Copy(all, v.validateIdentitiesInputs(signals, mask))
+
+	obs.check(all)
+	return newErrors(all)
+}
 
-	return all.Filter()
+// validateFormat checks that the proof is present and the signals match the
+// layout, the rest of the checks can't be done otherwise
+func (v *Verifier) validateFormat(zkProof zkptypes.ZKProof) val.Errors {
+	count := v.opts.layout.Count
+	return val.Errors{
+		"zk_proof/proof": verificationErr(
+			val.Validate(zkProof.Proof, val.Required),
+			CodeMalformedProof, NoSignal, nil, nil,
+		),
+		"zk_proof/pub_signals": verificationErr(
+			val.Validate(zkProof.PubSignals, val.Required, val.Length(count, count)),
+			CodeMalformedProof, NoSignal, count, len(zkProof.PubSignals),
+		),
+	}
 }
 
-func (v *Verifier) validateBirthDate(signals []string) val.Errors {
-	if v.opts.age == -1 {
+// validateSelector checks the selector value, when WithProofSelectorValue is
+// set, and decodes it
+func (v *Verifier) validateSelector(signals []string) (SelectorMask, error) {
+	err := validateOnOptSet(signals[Selector], v.opts.proofSelectorValue, val.In(v.opts.proofSelectorValue))
+	if err != nil {
+		return 0, verificationErr(err, CodeSelectorMismatch, Selector, v.opts.proofSelectorValue, signals[Selector])
+	}
+
+	mask, err := ParseSelectorMask(signals[Selector])
+	if err != nil {
+		return 0, verificationErr(err, CodeMalformedProof, Selector, nil, signals[Selector])
+	}
+
+	return mask, nil
+}
+
+func (v *Verifier) validateNullifier(signals []string) error {
+	return verificationErr(
+		val.Validate(signals[Nullifier], val.Required),
+		CodeNullifierMissing, Nullifier, nil, signals[Nullifier],
+	)
+}
+
+func (v *Verifier) validateEventID(signals []string) error {
+	return verificationErr(
+		validateOnOptSet(signals[EventID], v.opts.eventID, val.In(v.opts.eventID)),
+		CodeEventIDMismatch, EventID, v.opts.eventID, signals[EventID],
+	)
+}
+
+func (v *Verifier) validateEventData(signals []string) error {
+	return verificationErr(
+		validateOnOptSet(signals[EventData], v.opts.eventDataRule, v.opts.eventDataRule),
+		CodeEventDataMismatch, EventData, nil, signals[EventData],
+	)
+}
+
+func (v *Verifier) validateCitizenship(signals []string, mask SelectorMask) error {
+	allowed, excluded := v.opts.citizenships, v.opts.excludedCitizenships
+	if len(allowed) == 0 && len(excluded) == 0 {
+		return nil
+	}
+
+	option := "WithCitizenships"
+	if len(allowed) == 0 {
+		option = "WithCitizenshipsExcluded"
+	}
+
+	citizenship := canonicalCountry(decodeInt(signals[Citizenship]))
+	if err := requireSelected(mask, option, Citizenship); err != nil {
+		return verificationErr(err, CodeFieldNotSelected, Citizenship, nil, nil)
+	}
+
+	return firstError(
+		verificationErr(
+			validateOnOptSet(citizenship, allowed, val.In(allowed...)),
+			CodeCitizenshipNotAllowed, Citizenship, allowed, citizenship,
+		),
+		verificationErr(
+			validateOnOptSet(citizenship, excluded, val.NotIn(excluded...)),
+			CodeCitizenshipExcluded, Citizenship, excluded, citizenship,
+		),
+	)
+}
+
+func (v *Verifier) validateBirthDate(signals []string, mask SelectorMask) val.Errors {
+	earliest, latest := v.birthDateRange()
+	if earliest.IsZero() && latest.IsZero() {
 		return nil
 	}
 
-	allowedBirthDate := time.Now().UTC().AddDate(-v.opts.age, 0, 0)
-	return ORError(
-		val.Validate(signals[BirthDate], val.Required, beforeDate(allowedBirthDate)),
-		val.Validate(signals[BirthdateUpperBound], val.Required, equalDate(allowedBirthDate)),
-		[2]string{"pub_signals/birth_date", "pub_signals/birth_date_upper_bound"},
+	option := v.latestBirthDateOption()
+	if latest.IsZero() {
+		option = v.earliestBirthDateOption()
+	}
+	decode := birthDateDecoder(v.now())
+	selected := requireSelected(mask, option, BirthDate)
+
+	// the birth date itself must be in range, or the bounds must match the
+	// range edges: the earlier the upper bound is, the higher the age
+	var (
+		direct error
+		bounds = make(val.Errors, 2)
 	)
+	if !latest.IsZero() {
+		direct = verificationErr(
+			firstError(selected, val.Validate(signals[BirthDate], val.Required, beforeDate(latest, decode))),
+			CodeAgeTooLow, BirthDate, latest, decodeDate(signals[BirthDate], decode),
+		)
+		bounds["pub_signals/birth_date_upper_bound"] = verificationErr(
+			firstError(
+				requireSelected(mask, v.latestBirthDateOption(), BirthdateUpperBound),
+				val.Validate(signals[BirthdateUpperBound], val.Required, equalDate(latest, decode)),
+			),
+			CodeAgeTooLow, BirthdateUpperBound, latest, decodeDate(signals[BirthdateUpperBound], decode),
+		)
+	}
+	if !earliest.IsZero() {
+		direct = firstError(direct, verificationErr(
+			firstError(selected, val.Validate(signals[BirthDate], val.Required, afterDate(earliest, decode))),
+			CodeAgeTooHigh, BirthDate, earliest, decodeDate(signals[BirthDate], decode),
+		))
+		bounds["pub_signals/birth_date_lower_bound"] = verificationErr(
+			firstError(
+				requireSelected(mask, v.earliestBirthDateOption(), BirthdateLowerBound),
+				val.Validate(signals[BirthdateLowerBound], val.Required, equalDate(earliest, decode)),
+			),
+			CodeAgeTooHigh, BirthdateLowerBound, earliest, decodeDate(signals[BirthdateLowerBound], decode),
+		)
+	}
+
+	// OR logic, as in ORError: when both fail, the errors of the bounds are
+	// reported, unless only the birth date is enabled in selector
+	switch {
+	case direct == nil || bounds.Filter() == nil:
+		return val.Errors{"pub_signals/birth_date": nil}
+	case mask.Has(SelectorBirthDate) && errors.Is(newErrors(bounds), CodeFieldNotSelected):
+		return val.Errors{"pub_signals/birth_date": direct}
+	}
+	return bounds
+}
+
+// birthDateRange returns the earliest and the latest allowed birth dates,
+// inclusive, from the age and birth date options. Zero time means no limit.
+func (v *Verifier) birthDateRange() (earliest, latest time.Time) {
+	now := v.now()
+	if v.opts.age != -1 {
+		latest = now.AddDate(-v.opts.age, 0, 0)
+	}
+	if before := v.opts.birthDateBefore; !before.IsZero() {
+		latest = earlierDate(latest, before.AddDate(0, 0, -1))
+	}
+
+	if v.opts.maxAge > 0 {
+		earliest = now.AddDate(-v.opts.maxAge, 0, 1)
+	}
+	if after := v.opts.birthDateAfter; !after.IsZero() {
+		earliest = laterDate(earliest, after.AddDate(0, 0, 1))
+	}
+
+	return earliest, latest
+}
+
+// latestBirthDateOption is the name of the option for selector errors, which
+// limits the latest birth date with the upper bound
+func (v *Verifier) latestBirthDateOption() string {
+	if v.opts.age != -1 {
+		return "WithAgeAbove"
+	}
+	return "WithBirthDateBefore"
+}
+
+// earliestBirthDateOption is the name of the option for selector errors, which
+// limits the earliest birth date with the lower bound
+func (v *Verifier) earliestBirthDateOption() string {
+	if v.opts.maxAge > 0 {
+		return "WithAgeBelow"
+	}
+	return "WithBirthDateAfter"
 }
 
 func (v *Verifier) validatePassportExpiration(signals []string) val.Errors {
+	now := v.now()
+	decode := expirationDateDecoder(now)
 	return val.Errors{
-		"pub_signals/expiration_date_lower_bound": val.Validate(
-			signals[ExpirationDateLowerBound],
-			val.When(!isEmptyZKDate(signals[ExpirationDateLowerBound]), equalDate(time.Now().UTC())),
+		"pub_signals/expiration_date_lower_bound": verificationErr(
+			val.Validate(
+				signals[ExpirationDateLowerBound],
+				val.When(!IsEmptyZKDate(signals[ExpirationDateLowerBound]), equalDate(now, decode)),
+			),
+			CodeExpirationBoundMismatch, ExpirationDateLowerBound, now, decodeDate(signals[ExpirationDateLowerBound], decode),
 		),
-		"pub_signals/expiration_date": val.Validate(
-			signals[ExpirationDate],
-			val.When(!isEmptyZKDate(signals[ExpirationDate]), afterDate(time.Now().UTC())),
+		"pub_signals/expiration_date": verificationErr(
+			val.Validate(
+				signals[ExpirationDate],
+				val.When(!IsEmptyZKDate(signals[ExpirationDate]), afterDate(now, decode)),
+			),
+			CodePassportExpired, ExpirationDate, now, decodeDate(signals[ExpirationDate], decode),
 		),
 	}
 }
 
-// ZKP sets dates to 0 or 52983525027888 if date is not used or is not present in selector
-func isEmptyZKDate(dateStr string) bool {
-	return dateStr == "0" || dateStr == "52983525027888"
+// now returns the current time from the configured clock in UTC
+func (v *Verifier) now() time.Time {
+	return v.opts.clock().UTC()
 }
 
-func (v *Verifier) validateIdentitiesInputs(signals []string) val.Errors {
+func (v *Verifier) validateIdentitiesInputs(signals []string, mask SelectorMask) val.Errors {
 	counter, err := strconv.ParseInt(signals[IdentityCounterUpperBound], 10, 64)
 	if err != nil {
-		return val.Errors{"pub_signals/identity_counter_upper_bound": err}
+		return val.Errors{"pub_signals/identity_counter_upper_bound": verificationErr(
+			err, CodeMalformedProof, IdentityCounterUpperBound, nil, signals[IdentityCounterUpperBound],
+		)}
 	}
 
 	// ZKP generates a timestamp upper bound as regular unix timestamp, so or time validation is not suitable here
 	timestamp, err := strconv.ParseInt(signals[TimestampUpperBound], 10, 64)
 	if err != nil {
-		return val.Errors{"pub_signals/timestamp_upper_bound": err}
-	}
-
-	return ORError(
-		val.Validate(counter, val.When(
-			v.opts.maxIdentitiesCount != -1,
-			val.Max(v.opts.maxIdentitiesCount),
-		)),
-		validateOnOptSet(
-			time.Unix(timestamp, 0),
-			v.opts.maxIdentityCreationTimestamp,
-			val.Max(v.opts.maxIdentityCreationTimestamp),
-		),
+		return val.Errors{"pub_signals/timestamp_upper_bound": verificationErr(
+			err, CodeMalformedProof, TimestampUpperBound, nil, signals[TimestampUpperBound],
+		)}
+	}
+
+	var counterErr, timestampErr error
+	if v.opts.maxIdentitiesCount != -1 {
+		counterErr = verificationErr(
+			firstError(
+				requireSelected(mask, "WithIdentitiesCounter", IdentityCounterUpperBound),
+				val.Validate(counter, val.Max(v.opts.maxIdentitiesCount)),
+			),
+			CodeIdentityCounterExceeded, IdentityCounterUpperBound, v.opts.maxIdentitiesCount, signals[IdentityCounterUpperBound],
+		)
+	}
+	if !v.opts.maxIdentityCreationTimestamp.IsZero() {
+		timestampErr = verificationErr(
+			firstError(
+				requireSelected(mask, "WithIdentitiesCreationTimestampLimit", TimestampUpperBound),
+				val.Validate(time.Unix(timestamp, 0), val.Max(v.opts.maxIdentityCreationTimestamp)),
+			),
+			CodeIdentityTooOld, TimestampUpperBound, v.opts.maxIdentityCreationTimestamp, signals[TimestampUpperBound],
+		)
+	}
+
+	return ORError(counterErr, timestampErr,
 		[2]string{"pub_signals/identity_counter_upper_bound", "pub_signals/timestamp_upper_bound"},
 	)
 }
@@ -1,42 +1,51 @@
 package zkverifier_kit
 
 import (
-	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"math"
 	"os"
+	"slices"
 	"testing"
+	"time"
 
 	zkptypes "github.com/iden3/go-rapidsnark/types"
 	"github.com/rarimo/zkverifier-kit/identity"
 	"github.com/rarimo/zkverifier-kit/internal/testutil"
+	"github.com/rarimo/zkverifier-kit/nullifier"
 	"github.com/stretchr/testify/assert"
 )
 
-// !!!NOTE: Tests will fail if ZKP is not generated at the same date, when these tests are run.
-// This is because of the expiration_lower_bound check, that verifies that this signal is equal to
-// the current date.
+// NOTE: The expiration_lower_bound check verifies that this signal is equal to
+// the current date, so the tests use a fixed clock set to the proof generation
+// date, see proofDate.
 
 const (
-	validAddress   = "rarimo1exzw7q2fytyrurkp5s7tm7ek720we9ejwujf2h"
-	invalidAddress = "rarimo1nzmzvnr8yk98a9qxgkr0rrmmza7lhj90h9zycl"
-
 	higherAge = 98
 	lowerAge  = 13
 	equalAge  = 18
 
 	ukrCitizenship = "UKR"
 	usaCitizenship = "USA"
-	engCitizenship = "ENG"
+	gbrCitizenship = "GBR"
 
 	validEventID   = "304358862882731539112827930982999386691702727710421481944329166126417129570"
 	invalidEventID = "AC42D1A986804618C7A793FBE814D9B31E47BE51E082806363DCA6958F3062"
 
 	storedRoot = "1fd232b83b1927f2a8ede62ffe15c31d18782dd513e08f4aabeaf2e8e4c32417"
 
+	// validSelector is the selector of validProof: nullifier, citizenship,
+	// identity timestamp and counter, expiration and birth date upper bounds
+	validSelector = "23073"
+
 	maxTimestamp = math.MaxInt32
 )
 
+// proofDate is the date when validProof was generated, it is revealed in
+// ExpirationDateLowerBound signal
+var proofDate = time.Date(2024, 5, 24, 12, 0, 0, 0, time.UTC)
+
 const verificationKeyFile = "example_verification_key.json"
 
 var validProof = zkptypes.ZKProof{
@@ -135,6 +144,11 @@ func TestNewPassportVerifier(t *testing.T) {
 			name: "Neither key nor file are specified",
 			want: ErrVerificationKeyRequired.Error(),
 		},
+		{
+			name: "Malformed key",
+			key:  []byte("key"),
+			want: ErrInvalidVerificationKey.Error(),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -159,7 +173,7 @@ func TestVerifyProof(t *testing.T) {
 	var (
 		defaultVerifier = identity.NewVerifier(new(testutil.MockCaller).WithRoot(storedRoot), 0)
 		badVerifier     = identity.NewVerifier(new(testutil.MockCaller).WithRoot("ffffff"), 0)
-		invalidKey      = bytes.Replace(verificationKey, []byte("1"), []byte("0"), -1)
+		invalidKey      = mismatchedKey(t, 1)
 	)
 
 	testCases := []struct {
@@ -172,44 +186,30 @@ func TestVerifyProof(t *testing.T) {
 		{
 			name: "Matching citizenship",
 			initOpts: []VerifyOption{
-				WithVerificationKeyFile(verificationKeyFile),
-				WithProofSelectorValue("23073"),
+				WithProofSelectorValue(validSelector),
 				WithCitizenships(ukrCitizenship),
 			},
 			want: "",
 		},
 		{
-			name: "Non-matching citizenship",
+			name: "Any selector without the option",
 			initOpts: []VerifyOption{
-				WithVerificationKeyFile(verificationKeyFile),
-				WithProofSelectorValue("23073"),
-				WithCitizenships(engCitizenship, usaCitizenship),
-			},
-			want: "pub_signals/citizenship: must be a valid value",
-		},
-		{
-			name: "Valid address",
-			initOpts: []VerifyOption{
-				WithVerificationKeyFile(verificationKeyFile),
-				WithProofSelectorValue("23073"),
-				WithRarimoAddress(validAddress),
+				WithCitizenships(ukrCitizenship),
 			},
 			want: "",
 		},
 		{
-			name: "Invalid address",
+			name: "Non-matching citizenship",
 			initOpts: []VerifyOption{
-				WithVerificationKeyFile(verificationKeyFile),
-				WithProofSelectorValue("23073"),
-				WithRarimoAddress(invalidAddress),
+				WithProofSelectorValue(validSelector),
+				WithCitizenships(gbrCitizenship, usaCitizenship),
 			},
-			want: "pub_signals/event_data: must be a valid value",
+			want: "pub_signals/citizenship: must be a valid value",
 		},
 		{
 			name: "Valid event data",
 			initOpts: []VerifyOption{
-				WithVerificationKeyFile(verificationKeyFile),
-				WithProofSelectorValue("23073"),
+				WithProofSelectorValue(validSelector),
 				WithEventData(validEventData),
 			},
 			want: "",
@@ -217,17 +217,15 @@ func TestVerifyProof(t *testing.T) {
 		{
 			name: "Invalid event data",
 			initOpts: []VerifyOption{
-				WithVerificationKeyFile(verificationKeyFile),
-				WithProofSelectorValue("23073"),
+				WithProofSelectorValue(validSelector),
 				WithEventData(invalidEventData),
 			},
-			want: "pub_signals/event_data: must be a valid value",
+			want: "pub_signals/event_data: event data does not match",
 		},
 		{
 			name: "Lower age",
 			initOpts: []VerifyOption{
-				WithVerificationKeyFile(verificationKeyFile),
-				WithProofSelectorValue("23073"),
+				WithProofSelectorValue(validSelector),
 				WithAgeAbove(lowerAge),
 			}, verifyOpts: []VerifyOption{
 				WithAgeAbove(lowerAge),
@@ -238,8 +236,7 @@ func TestVerifyProof(t *testing.T) {
 		{
 			name: "Equal age",
 			initOpts: []VerifyOption{
-				WithVerificationKeyFile(verificationKeyFile),
-				WithProofSelectorValue("23073"),
+				WithProofSelectorValue(validSelector),
 				WithAgeAbove(equalAge),
 			},
 			want: "",
@@ -247,8 +244,7 @@ func TestVerifyProof(t *testing.T) {
 		{
 			name: "Higher age",
 			initOpts: []VerifyOption{
-				WithVerificationKeyFile(verificationKeyFile),
-				WithProofSelectorValue("23073"),
+				WithProofSelectorValue(validSelector),
 				WithAgeAbove(higherAge),
 			},
 			verifyOpts: []VerifyOption{
@@ -256,11 +252,26 @@ func TestVerifyProof(t *testing.T) {
 			},
 			want: "pub_signals/birth_date_upper_bound: dates are not equal",
 		},
+		{
+			name: "Born before the upper bound",
+			initOpts: []VerifyOption{
+				WithProofSelectorValue(validSelector),
+				WithBirthDateBefore(proofDate.AddDate(-equalAge, 0, 1)),
+			},
+			want: "",
+		},
+		{
+			name: "Age between without lower bound",
+			initOpts: []VerifyOption{
+				WithProofSelectorValue(validSelector),
+				WithAgeBetween(equalAge, 25),
+			},
+			want: "pub_signals/birth_date_lower_bound: option WithAgeBelow requires field birth_date_upper_bound, but selector does not enable it",
+		},
 		{
 			name: "Valid event ID",
 			initOpts: []VerifyOption{
-				WithVerificationKeyFile(verificationKeyFile),
-				WithProofSelectorValue("23073"),
+				WithProofSelectorValue(validSelector),
 				WithEventID(validEventID),
 			},
 			want: "",
@@ -268,8 +279,7 @@ func TestVerifyProof(t *testing.T) {
 		{
 			name: "Invalid event ID",
 			initOpts: []VerifyOption{
-				WithVerificationKeyFile(verificationKeyFile),
-				WithProofSelectorValue("23073"),
+				WithProofSelectorValue(validSelector),
 				WithEventID(invalidEventID),
 			},
 			want: "pub_signals/event_id: must be a valid value",
@@ -277,8 +287,7 @@ func TestVerifyProof(t *testing.T) {
 		{
 			name: "Valid counter without timestamp",
 			initOpts: []VerifyOption{
-				WithVerificationKeyFile(verificationKeyFile),
-				WithProofSelectorValue("23073"),
+				WithProofSelectorValue(validSelector),
 				WithIdentitiesCounter(999),
 			},
 			want: "",
@@ -286,8 +295,7 @@ func TestVerifyProof(t *testing.T) {
 		{
 			name: "Valid timestamp without counter",
 			initOpts: []VerifyOption{
-				WithVerificationKeyFile(verificationKeyFile),
-				WithProofSelectorValue("23073"),
+				WithProofSelectorValue(validSelector),
 				WithIdentitiesCreationTimestampLimit(maxTimestamp),
 			},
 			want: "",
@@ -295,8 +303,7 @@ func TestVerifyProof(t *testing.T) {
 		{
 			name: "Valid counter with invalid timestamp",
 			initOpts: []VerifyOption{
-				WithVerificationKeyFile(verificationKeyFile),
-				WithProofSelectorValue("23073"),
+				WithProofSelectorValue(validSelector),
 				WithIdentitiesCounter(999),
 				WithIdentitiesCreationTimestampLimit(0),
 			},
@@ -305,8 +312,7 @@ func TestVerifyProof(t *testing.T) {
 		{
 			name: "Valid timestamp with invalid counter",
 			initOpts: []VerifyOption{
-				WithVerificationKeyFile(verificationKeyFile),
-				WithProofSelectorValue("23073"),
+				WithProofSelectorValue(validSelector),
 				WithIdentitiesCounter(0),
 				WithIdentitiesCreationTimestampLimit(maxTimestamp),
 			},
@@ -315,8 +321,7 @@ func TestVerifyProof(t *testing.T) {
 		{
 			name: "Invalid counter and timestamp",
 			initOpts: []VerifyOption{
-				WithVerificationKeyFile(verificationKeyFile),
-				WithProofSelectorValue("23073"),
+				WithProofSelectorValue(validSelector),
 				WithIdentitiesCounter(0),
 				WithIdentitiesCreationTimestampLimit(0),
 			},
@@ -324,13 +329,12 @@ func TestVerifyProof(t *testing.T) {
 				WithIdentitiesCounter(0),
 				WithIdentitiesCreationTimestampLimit(1684839455),
 			},
-			want: "pub_signals/timestamp_upper_bound: must be no greater than 2023-05-23 13:57:35 +0300 EEST",
+			want: fmt.Sprintf("pub_signals/timestamp_upper_bound: must be no greater than %s", time.Unix(1684839455, 0)),
 		},
 		{
 			name: "No options",
 			initOpts: []VerifyOption{
-				WithVerificationKeyFile(verificationKeyFile),
-				WithProofSelectorValue("23073"),
+				WithProofSelectorValue(validSelector),
 			},
 			want: "",
 		},
@@ -338,28 +342,25 @@ func TestVerifyProof(t *testing.T) {
 			name: "All valid options",
 			initOpts: []VerifyOption{
 				WithAgeAbove(equalAge),
-				WithProofSelectorValue("23073"),
+				WithProofSelectorValue(validSelector),
 				WithCitizenships(ukrCitizenship),
 				WithEventID(validEventID),
 				WithIdentityVerifier(defaultVerifier),
 				WithIdentitiesCounter(999),
 				WithIdentitiesCreationTimestampLimit(maxTimestamp),
-				WithVerificationKeyFile(verificationKeyFile),
 			},
 			verifyOpts: []VerifyOption{
-				WithRarimoAddress(validAddress),
+				WithEventData(validEventData),
 			},
 			want: "",
 		},
 		{
 			name: "Invalid identity verifier",
 			initOpts: []VerifyOption{
-				WithVerificationKeyFile(verificationKeyFile),
 				WithIdentityVerifier(badVerifier),
-				WithProofSelectorValue("23073"),
+				WithProofSelectorValue(validSelector),
 			},
 			verifyOpts: []VerifyOption{
-				WithVerificationKeyFile(verificationKeyFile),
 				WithIdentityVerifier(badVerifier),
 			},
 			want: fmt.Sprintf("pub_signals/id_state_root: %s", identity.ErrInvalidRoot),
@@ -367,26 +368,31 @@ func TestVerifyProof(t *testing.T) {
 		{
 			name: "Invalid verification key",
 			initOpts: []VerifyOption{
-				WithProofSelectorValue("23073"),
+				WithProofSelectorValue(validSelector),
 			},
 			key:  invalidKey,
 			want: "groth16 verification failed",
 		},
 	}
 
+	// validProof does not match the example key, so the signals are proven
+	// with a synthetic key
+	validKey, proof := testutil.Groth16Proof(validProof.PubSignals)
+
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			key := tc.key
 			if len(key) == 0 {
-				key = verificationKey
+				key = validKey
 			}
 
-			verifier, err := NewPassportVerifier(tc.key, tc.initOpts...)
+			opts := append([]VerifyOption{WithNow(proofDate)}, tc.initOpts...)
+			verifier, err := NewPassportVerifier(key, opts...)
 			if err != nil {
 				t.Fatal(err)
 			}
 
-			err = verifier.VerifyProof(validProof, tc.verifyOpts...)
+			err = verifier.VerifyProof(proof, tc.verifyOpts...)
 			if tc.want == "" {
 				assert.NoError(t, err)
 				return
@@ -396,3 +402,280 @@ func TestVerifyProof(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifyProofContext(t *testing.T) {
+	rv := identity.NewVerifier(new(testutil.MockCaller).WithRoot(storedRoot), time.Second)
+	verifier, err := NewPassportVerifier(verificationKey,
+		WithNow(proofDate),
+		WithProofSelectorValue(validSelector),
+		WithIdentityVerifier(rv),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = verifier.VerifyProofContext(ctx, validProof)
+	assert.ErrorIs(t, err, identity.ErrContractCall)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestVerifyProof_NullifierStore(t *testing.T) {
+	store := nullifier.NewMemoryStore()
+	invalidKey := mismatchedKey(t, 1)
+
+	verifier, err := NewPassportVerifier(invalidKey,
+		WithNow(proofDate),
+		WithProofSelectorValue(validSelector),
+		WithNullifierStore(store),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// invalid proof must not consume the nullifier
+	assert.ErrorContains(t, verifier.VerifyProof(validProof), "groth16 verification failed")
+	assert.NoError(t, store.Reserve(context.Background(), validProof.PubSignals[EventID], validProof.PubSignals[Nullifier]))
+}
+
+func TestVerifyProof_NullifierReplay(t *testing.T) {
+	verifier, proof := generatedProof(t, nil, WithNullifierStore(nullifier.NewMemoryStore()))
+
+	if err := verifier.VerifyProof(proof); err != nil {
+		t.Fatal(err)
+	}
+
+	err := verifier.VerifyProof(proof)
+	assert.True(t, errors.Is(err, CodeNullifierUsed), err)
+	assert.True(t, errors.Is(err, nullifier.ErrNullifierUsed), err)
+}
+
+func TestVerifyProof_Identities(t *testing.T) {
+	// validProof reveals the identity counter 1 and the timestamp 1713436478
+	testCases := []struct {
+		name    string
+		opts    []VerifyOption
+		signals map[PubSignal]string
+		want    Code
+	}{
+		{
+			name: "Exceeded counter without timestamp",
+			opts: []VerifyOption{WithIdentitiesCounter(0)},
+		},
+		{
+			name: "Too old timestamp without counter",
+			opts: []VerifyOption{WithIdentitiesCreationTimestampLimit(1684839455)},
+		},
+		{
+			name: "Exceeded counter with valid timestamp",
+			opts: []VerifyOption{WithIdentitiesCounter(0), WithIdentitiesCreationTimestampLimit(maxTimestamp)},
+		},
+		{
+			name: "Too old timestamp with valid counter",
+			opts: []VerifyOption{WithIdentitiesCounter(1), WithIdentitiesCreationTimestampLimit(1684839455)},
+		},
+		{
+			name: "Exceeded counter and too old timestamp",
+			opts: []VerifyOption{WithIdentitiesCounter(0), WithIdentitiesCreationTimestampLimit(1684839455)},
+			want: CodeIdentityTooOld,
+		},
+		{
+			name:    "Malformed counter without options",
+			signals: map[PubSignal]string{IdentityCounterUpperBound: "9223372036854775808"},
+			want:    CodeMalformedProof,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			verifier, proof := generatedProof(t, tc.signals, tc.opts...)
+			err := verifier.VerifyProof(proof)
+			if tc.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, tc.want), err)
+		})
+	}
+}
+
+func TestVerifyProof_PassportExpiration(t *testing.T) {
+	const emptyDate = "52983525027888"
+
+	testCases := []struct {
+		name    string
+		opts    []VerifyOption
+		signals map[PubSignal]string
+		want    Code
+	}{
+		{
+			name:    "Valid expiration date",
+			signals: map[PubSignal]string{ExpirationDate: EncodeZKDate(proofDate.AddDate(1, 0, 0))},
+		},
+		{
+			// the date is checked when revealed, whatever the selector is
+			name:    "Expired passport",
+			signals: map[PubSignal]string{ExpirationDate: EncodeZKDate(proofDate.AddDate(0, 0, -1))},
+			want:    CodePassportExpired,
+		},
+		{
+			name: "Lower bound is not today",
+			opts: []VerifyOption{WithNow(proofDate.AddDate(0, 0, 1))},
+			want: CodeExpirationBoundMismatch,
+		},
+		{
+			name:    "Empty dates are skipped",
+			opts:    []VerifyOption{WithNow(proofDate.AddDate(1, 0, 0))},
+			signals: map[PubSignal]string{ExpirationDate: emptyDate, ExpirationDateLowerBound: emptyDate},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			verifier, proof := generatedProof(t, tc.signals, tc.opts...)
+			err := verifier.VerifyProof(proof)
+			if tc.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, tc.want), err)
+		})
+	}
+}
+
+// generatedProof proves validProof signals, replaced with the given ones, with
+// a synthetic key, and returns the verifier of the proof selector
+func generatedProof(t *testing.T, replaced map[PubSignal]string, opts ...VerifyOption) (*Verifier, zkptypes.ZKProof) {
+	signals := slices.Clone(validProof.PubSignals)
+	for s, value := range replaced {
+		signals[s] = value
+	}
+	key, proof := testutil.Groth16Proof(signals)
+
+	opts = append([]VerifyOption{WithNow(proofDate), WithProofSelectorValue(signals[Selector])}, opts...)
+	verifier, err := NewPassportVerifier(key, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return verifier, proof
+}
+
+func TestVerifyProof_BirthDateRange(t *testing.T) {
+	const (
+		withBirthDate  = "23075" // validProof selector with birth date revealed
+		withBothBounds = "55841" // validProof selector with birth date lower bound
+		onlyLowerBound = "39457" // validProof selector without birth date upper bound
+	)
+
+	var (
+		birthDate      = EncodeZKDate(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+		birthDate1955  = EncodeZKDate(time.Date(1955, 7, 1, 0, 0, 0, 0, time.UTC))
+		lowerBound2625 = EncodeZKDate(time.Date(1998, 5, 25, 0, 0, 0, 0, time.UTC))
+		cutoff         = time.Date(2006, 3, 1, 0, 0, 0, 0, time.UTC)
+	)
+
+	testCases := []struct {
+		name    string
+		opts    []VerifyOption
+		signals map[PubSignal]string
+		want    Code
+	}{
+		{
+			name:    "Age between with bounds",
+			opts:    []VerifyOption{WithAgeBetween(18, 25)},
+			signals: map[PubSignal]string{Selector: withBothBounds, BirthdateLowerBound: lowerBound2625},
+		},
+		{
+			name:    "Age between without lower bound",
+			opts:    []VerifyOption{WithAgeBetween(18, 25)},
+			signals: map[PubSignal]string{Selector: withBothBounds},
+			want:    CodeAgeTooHigh,
+		},
+		{
+			name:    "Age below with birth date",
+			opts:    []VerifyOption{WithAgeBelow(30)},
+			signals: map[PubSignal]string{Selector: withBirthDate, BirthDate: birthDate},
+		},
+		{
+			name:    "Age below with old birth date",
+			opts:    []VerifyOption{WithAgeBelow(20)},
+			signals: map[PubSignal]string{Selector: withBirthDate, BirthDate: birthDate},
+			want:    CodeAgeTooHigh,
+		},
+		{
+			name:    "Born before with birth date",
+			opts:    []VerifyOption{WithBirthDateBefore(cutoff)},
+			signals: map[PubSignal]string{Selector: withBirthDate, BirthDate: birthDate},
+		},
+		{
+			name:    "Born before with upper bound",
+			opts:    []VerifyOption{WithBirthDateBefore(cutoff)},
+			signals: map[PubSignal]string{BirthdateUpperBound: EncodeZKDate(cutoff.AddDate(0, 0, -1))},
+		},
+		{
+			name:    "Born after with birth date",
+			opts:    []VerifyOption{WithBirthDateAfter(cutoff)},
+			signals: map[PubSignal]string{Selector: withBirthDate, BirthDate: birthDate},
+			want:    CodeAgeTooHigh,
+		},
+		{
+			name:    "Age above with last century birth date",
+			opts:    []VerifyOption{WithAgeAbove(equalAge)},
+			signals: map[PubSignal]string{Selector: withBirthDate, BirthDate: birthDate1955},
+		},
+		{
+			name:    "Age below with last century birth date",
+			opts:    []VerifyOption{WithAgeBelow(65)},
+			signals: map[PubSignal]string{Selector: withBirthDate, BirthDate: birthDate1955},
+			want:    CodeAgeTooHigh,
+		},
+		{
+			// the circuit does not constrain the upper bound, when only the
+			// lower one is enabled
+			name:    "Age above with only lower bound selected",
+			opts:    []VerifyOption{WithAgeAbove(equalAge)},
+			signals: map[PubSignal]string{Selector: onlyLowerBound},
+			want:    CodeFieldNotSelected,
+		},
+		{
+			name:    "Age between with only upper bound selected",
+			opts:    []VerifyOption{WithAgeBetween(18, 25)},
+			signals: map[PubSignal]string{BirthdateLowerBound: lowerBound2625},
+			want:    CodeFieldNotSelected,
+		},
+		{
+			name:    "Age below with only upper bound selected",
+			opts:    []VerifyOption{WithAgeBelow(26)},
+			signals: map[PubSignal]string{BirthdateLowerBound: lowerBound2625},
+			want:    CodeFieldNotSelected,
+		},
+		{
+			// the bounds are not enabled, so the error of the birth date is
+			// reported
+			name:    "Age below with birth date and upper bound only",
+			opts:    []VerifyOption{WithAgeBelow(20)},
+			signals: map[PubSignal]string{Selector: withBirthDate, BirthDate: birthDate},
+			want:    CodeAgeTooHigh,
+		},
+		{
+			name: "Earlier limit is used",
+			opts: []VerifyOption{WithAgeAbove(equalAge), WithBirthDateBefore(cutoff)},
+			want: CodeAgeTooLow,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			verifier, proof := generatedProof(t, tc.signals, tc.opts...)
+			err := verifier.VerifyProof(proof)
+			if tc.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, errors.Is(err, tc.want), err)
+		})
+	}
+}
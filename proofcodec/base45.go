@@ -0,0 +1,60 @@
+package proofcodec
+
+import (
+	"fmt"
+	"strings"
+)
+
+// base45Alphabet is the alphanumeric mode charset of QR codes, RFC 9285
+const base45Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// encodeBase45 encodes each 2 bytes into 3 characters, the last odd byte into 2
+func encodeBase45(data []byte) string {
+	var sb strings.Builder
+	sb.Grow((len(data)/2)*3 + len(data)%2*2)
+
+	for i := 0; i < len(data); i += 2 {
+		n, size := int(data[i]), 2
+		if i+1 < len(data) {
+			n, size = n<<8|int(data[i+1]), 3
+		}
+		for j := 0; j < size; j++ {
+			sb.WriteByte(base45Alphabet[n%45])
+			n /= 45
+		}
+	}
+
+	return sb.String()
+}
+
+func decodeBase45(s string) ([]byte, error) {
+	if len(s)%3 == 1 {
+		return nil, fmt.Errorf("invalid length %d", len(s))
+	}
+
+	data := make([]byte, 0, len(s)/3*2+1)
+	for i := 0; i < len(s); i += 3 {
+		chunk := s[i:min(i+3, len(s))]
+
+		n, weight := 0, 1
+		for _, c := range []byte(chunk) {
+			digit := strings.IndexByte(base45Alphabet, c)
+			if digit < 0 {
+				return nil, fmt.Errorf("invalid character %q at %d", c, i)
+			}
+			n += digit * weight
+			weight *= 45
+		}
+
+		switch {
+		case len(chunk) == 3 && n <= 0xffff:
+			data = append(data, byte(n>>8), byte(n))
+		case len(chunk) == 2 && n <= 0xff:
+			data = append(data, byte(n))
+		default:
+			return nil, fmt.Errorf("invalid chunk %q at %d", chunk, i)
+		}
+	}
+
+	return data, nil
+}
@@ -0,0 +1,243 @@
+package proofcodec
+
+import (
+	"errors"
+	"math/big"
+)
+
+// The point compression follows gnark-crypto: x is written as 32-byte
+// big-endian number, imaginary part first for G2, and the two top bits of the
+// first byte, which are always zero as p < 2^254, tell y apart
+const (
+	flagMask            = 0b11 << 6
+	flagInfinity        = 0b01 << 6
+	flagCompressedSmall = 0b10 << 6
+	flagCompressedLarge = 0b11 << 6
+
+	fieldSize = 32
+	g1Size    = fieldSize
+	g2Size    = 2 * fieldSize
+)
+
+var (
+	// fieldP is the base field modulus of BN254
+	fieldP, _ = new(big.Int).SetString("21888242871839275222246405745257275088696311157297823662689037894645226208583", 10)
+	// fieldHalf is (p-1)/2, the numbers above it are lexicographically largest
+	fieldHalf = new(big.Int).Rsh(fieldP, 1)
+	// sqrtExp is (p+1)/4, a^sqrtExp is the square root of a, as p = 3 mod 4
+	sqrtExp = new(big.Int).Rsh(new(big.Int).Add(fieldP, big.NewInt(1)), 2)
+
+	g1B = big.NewInt(3)
+	// g2B is 3/(9+u), the coefficient of the twist y^2 = x^3 + b
+	g2B = fp2{big.NewInt(3), new(big.Int)}.mul(fp2{big.NewInt(9), big.NewInt(1)}.inverse())
+)
+
+var errNotOnCurve = errors.New("point is not on curve")
+
+// compressG1 compresses affine point, nil x and y are the point at infinity
+func compressG1(x, y *big.Int) ([]byte, error) {
+	buf := make([]byte, g1Size)
+	if x == nil {
+		buf[0] = flagInfinity
+		return buf, nil
+	}
+
+	if x.Cmp(fieldP) >= 0 || y.Cmp(fieldP) >= 0 {
+		return nil, errors.New("coordinate is not in the field")
+	}
+	if !isSquareRoot(y, g1Rhs(x)) {
+		return nil, errNotOnCurve
+	}
+
+	x.FillBytes(buf)
+	buf[0] |= flagCompressedSmall
+	if y.Cmp(fieldHalf) > 0 {
+		buf[0] |= flagCompressedLarge
+	}
+
+	return buf, nil
+}
+
+// decompressG1 restores affine point, nil x and y are the point at infinity
+func decompressG1(buf []byte) (x, y *big.Int, err error) {
+	flags, x, err := readCompressed(buf[:fieldSize])
+	if err != nil || flags == flagInfinity {
+		return nil, nil, err
+	}
+
+	rhs := g1Rhs(x)
+	y = new(big.Int).Exp(rhs, sqrtExp, fieldP)
+	if !isSquareRoot(y, rhs) {
+		return nil, nil, errNotOnCurve
+	}
+	if (y.Cmp(fieldHalf) > 0) != (flags == flagCompressedLarge) {
+		y.Sub(fieldP, y).Mod(y, fieldP)
+	}
+
+	return x, y, nil
+}
+
+// compressG2 compresses affine point, nil x and y are the point at infinity
+func compressG2(x, y *fp2) ([]byte, error) {
+	buf := make([]byte, g2Size)
+	if x == nil {
+		buf[0] = flagInfinity
+		return buf, nil
+	}
+
+	if !x.inField() || !y.inField() {
+		return nil, errors.New("coordinate is not in the field")
+	}
+	if !y.mul(*y).equal(g2Rhs(*x)) {
+		return nil, errNotOnCurve
+	}
+
+	x[1].FillBytes(buf[:fieldSize])
+	x[0].FillBytes(buf[fieldSize:])
+	buf[0] |= flagCompressedSmall
+	if y.largest() {
+		buf[0] |= flagCompressedLarge
+	}
+
+	return buf, nil
+}
+
+// decompressG2 restores affine point, nil x and y are the point at infinity
+func decompressG2(buf []byte) (x, y *fp2, err error) {
+	flags, x1, err := readCompressed(buf[:fieldSize])
+	if err != nil || flags == flagInfinity {
+		return nil, nil, err
+	}
+	x0 := new(big.Int).SetBytes(buf[fieldSize:g2Size])
+	if x0.Cmp(fieldP) >= 0 {
+		return nil, nil, errors.New("coordinate is not in the field")
+	}
+
+	x = &fp2{x0, x1}
+	root, ok := g2Rhs(*x).sqrt()
+	if !ok {
+		return nil, nil, errNotOnCurve
+	}
+	if root.largest() != (flags == flagCompressedLarge) {
+		root = root.neg()
+	}
+
+	return x, &root, nil
+}
+
+// readCompressed reads the flags and the number of the first 32 bytes of
+// compressed point. The infinity must have zero number.
+func readCompressed(buf []byte) (byte, *big.Int, error) {
+	flags := buf[0] & flagMask
+
+	raw := make([]byte, fieldSize)
+	copy(raw, buf)
+	raw[0] &^= flagMask
+	n := new(big.Int).SetBytes(raw)
+
+	switch {
+	case flags == 0:
+		return 0, nil, errors.New("point is not compressed")
+	case flags == flagInfinity && n.Sign() != 0:
+		return 0, nil, errors.New("invalid point at infinity")
+	case n.Cmp(fieldP) >= 0:
+		return 0, nil, errors.New("coordinate is not in the field")
+	}
+
+	return flags, n, nil
+}
+
+// g1Rhs returns x^3 + 3
+func g1Rhs(x *big.Int) *big.Int {
+	rhs := new(big.Int).Exp(x, big.NewInt(3), fieldP)
+	return rhs.Add(rhs, g1B).Mod(rhs, fieldP)
+}
+
+func isSquareRoot(root, n *big.Int) bool {
+	return new(big.Int).Exp(root, big.NewInt(2), fieldP).Cmp(n) == 0
+}
+
+// fp2 is a0 + a1*u element of the quadratic extension with u^2 = -1
+type fp2 [2]*big.Int
+
+// g2Rhs returns x^3 + b of the twist
+func g2Rhs(x fp2) fp2 {
+	return x.mul(x).mul(x).add(g2B)
+}
+
+func (a fp2) add(b fp2) fp2 {
+	return fp2{mod(new(big.Int).Add(a[0], b[0])), mod(new(big.Int).Add(a[1], b[1]))}
+}
+
+func (a fp2) mul(b fp2) fp2 {
+	re := new(big.Int).Sub(new(big.Int).Mul(a[0], b[0]), new(big.Int).Mul(a[1], b[1]))
+	im := new(big.Int).Add(new(big.Int).Mul(a[0], b[1]), new(big.Int).Mul(a[1], b[0]))
+	return fp2{mod(re), mod(im)}
+}
+
+func (a fp2) neg() fp2 {
+	return fp2{mod(new(big.Int).Neg(a[0])), mod(new(big.Int).Neg(a[1]))}
+}
+
+// inverse returns (a0 - a1*u) / (a0^2 + a1^2)
+func (a fp2) inverse() fp2 {
+	norm := mod(new(big.Int).Add(new(big.Int).Mul(a[0], a[0]), new(big.Int).Mul(a[1], a[1])))
+	inv := new(big.Int).ModInverse(norm, fieldP)
+	return fp2{mod(new(big.Int).Mul(a[0], inv)), mod(new(big.Int).Neg(new(big.Int).Mul(a[1], inv)))}
+}
+
+// sqrt finds the square root with the norm: if x^2 = a, then x0^2 is
+// (a0 ± sqrt(a0^2 + a1^2)) / 2 and x1 = a1 / (2*x0)
+func (a fp2) sqrt() (fp2, bool) {
+	norm := mod(new(big.Int).Add(new(big.Int).Mul(a[0], a[0]), new(big.Int).Mul(a[1], a[1])))
+	normRoot := new(big.Int).Exp(norm, sqrtExp, fieldP)
+	if !isSquareRoot(normRoot, norm) {
+		return fp2{}, false
+	}
+
+	half := new(big.Int).ModInverse(big.NewInt(2), fieldP)
+	for _, r := range []*big.Int{normRoot, new(big.Int).Neg(normRoot)} {
+		x0Square := mod(new(big.Int).Mul(new(big.Int).Add(a[0], r), half))
+		x0 := new(big.Int).Exp(x0Square, sqrtExp, fieldP)
+		if !isSquareRoot(x0, x0Square) {
+			continue
+		}
+
+		var root fp2
+		if x0.Sign() == 0 {
+			// a1 is zero and a0 is not a square: x = sqrt(-a0)*u
+			x1Square := mod(new(big.Int).Neg(a[0]))
+			root = fp2{x0, new(big.Int).Exp(x1Square, sqrtExp, fieldP)}
+		} else {
+			inv := new(big.Int).ModInverse(new(big.Int).Lsh(x0, 1), fieldP)
+			root = fp2{x0, mod(new(big.Int).Mul(a[1], inv))}
+		}
+
+		if root.mul(root).equal(a) {
+			return root, true
+		}
+	}
+
+	return fp2{}, false
+}
+
+// largest compares the imaginary parts, or the real ones if the imaginary part
+// is zero, with (p-1)/2
+func (a fp2) largest() bool {
+	if a[1].Sign() == 0 {
+		return a[0].Cmp(fieldHalf) > 0
+	}
+	return a[1].Cmp(fieldHalf) > 0
+}
+
+func (a fp2) equal(b fp2) bool {
+	return a[0].Cmp(b[0]) == 0 && a[1].Cmp(b[1]) == 0
+}
+
+func (a fp2) inField() bool {
+	return a[0].Cmp(fieldP) < 0 && a[1].Cmp(fieldP) < 0
+}
+
+func mod(n *big.Int) *big.Int {
+	return n.Mod(n, fieldP)
+}
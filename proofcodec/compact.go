@@ -0,0 +1,358 @@
+package proofcodec
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/iden3/go-iden3-crypto/constants"
+	zkptypes "github.com/iden3/go-rapidsnark/types"
+	kit "github.com/rarimo/zkverifier-kit"
+)
+
+// CompactVersion is the version of the compact encoding, written in its first
+// byte
+const CompactVersion byte = 1
+
+// flagSignalsOmitted is set in the second byte, when the bitmap of present
+// signals follows the signals count
+const flagSignalsOmitted byte = 1
+
+var ErrUnsupportedVersion = errors.New("unsupported compact proof version")
+
+// unusedSignal is a signal of the default layout, which is not used when none
+// of the fields are enabled in selector. Such signal is omitted from the compact
+// encoding when it is equal to value.
+type unusedSignal struct {
+	fields []kit.SelectorField
+	value  string
+}
+
+// unusedSignals are indexed by the positions in the default signal layout, the
+// signals, which are always used, are nil
+var unusedSignals = func() []*unusedSignal {
+	signals := make([]*unusedSignal, kit.DefaultSignalLayout().Count)
+	add := func(i int, value string, fields ...kit.SelectorField) {
+		signals[i] = &unusedSignal{fields: fields, value: value}
+	}
+
+	add(int(kit.BirthDate), kit.EmptyZKDate, kit.SelectorBirthDate)
+	add(int(kit.ExpirationDate), kit.EmptyZKDate, kit.SelectorExpirationDate)
+	add(3, "0", kit.SelectorName)
+	add(4, "0", kit.SelectorName) // name residual
+	add(5, "0", kit.SelectorNationality)
+	add(int(kit.Citizenship), "0", kit.SelectorCitizenship)
+	add(7, "0", kit.SelectorSex)
+	add(8, "0", kit.SelectorDocumentNumber)
+	add(13, "0", kit.SelectorTimestampLowerBound)
+	add(int(kit.TimestampUpperBound), "0", kit.SelectorTimestampUpperBound)
+	add(15, "0", kit.SelectorIdentityCounterLowerBound)
+	add(int(kit.IdentityCounterUpperBound), "0", kit.SelectorIdentityCounterUpperBound)
+	// the bits of the birth date bounds are crossed, see kit.PubSignal.SelectorField
+	for _, s := range []kit.PubSignal{kit.BirthdateLowerBound, kit.BirthdateUpperBound} {
+		field, _ := s.SelectorField()
+		add(int(s), kit.EmptyZKDate, field)
+	}
+	add(int(kit.ExpirationDateLowerBound), kit.EmptyZKDate, kit.SelectorExpirationDateLowerBound)
+	add(20, kit.EmptyZKDate, kit.SelectorExpirationDateUpperBound)
+	add(21, "0", kit.SelectorCitizenshipWhitelist, kit.SelectorCitizenshipBlacklist) // citizenship mask
+
+	return signals
+}()
+
+type compactOptions struct {
+	omitUnused bool
+}
+
+// CompactOption configures EncodeCompact
+type CompactOption func(*compactOptions)
+
+// WithUnusedSignalsOmitted omits the signals of the default layout, which are
+// not used according to the selector, and have the value that the circuit sets
+// for the disabled fields: "0" or kit.EmptyZKDate for dates. The decoder
+// restores them, so the round-trip is still exact. The option has no effect on
+// the proofs with other signals count or invalid selector.
+func WithUnusedSignalsOmitted() CompactOption {
+	return func(o *compactOptions) {
+		o.omitUnused = true
+	}
+}
+
+// EncodeCompact encodes the Groth16 proof into a compact binary form, which fits
+// into QR codes and deep links:
+//
+//	version     1 byte, CompactVersion
+//	flags       1 byte
+//	pi_a        32 bytes, compressed G1 point
+//	pi_b        64 bytes, compressed G2 point
+//	pi_c        32 bytes, compressed G1 point
+//	count       uvarint, the number of public signals
+//	bitmap      (count+7)/8 bytes of present signals, when they are omitted
+//	signals     32 bytes big-endian each
+//
+// The points are compressed as in gnark-crypto. The points must be affine, as
+// in EncodeCalldata, and on the curve, the signals must be in the scalar field.
+// DecodeCompact returns the proof normalized as by Decode, so the round-trip is
+// exact for the proofs with decimal numbers.
+func EncodeCompact(proof zkptypes.ZKProof, opts ...CompactOption) ([]byte, error) {
+	var o compactOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	proof, err := normalize(proof)
+	if err != nil {
+		return nil, err
+	}
+	if proof.Proof.Protocol != protocolGroth16 {
+		return nil, fmt.Errorf("%w: unsupported protocol %q", ErrInvalidProof, proof.Proof.Protocol)
+	}
+
+	buf := []byte{CompactVersion, 0}
+	for _, p := range []struct {
+		name     string
+		compress func() ([]byte, error)
+	}{
+		{"pi_a", func() ([]byte, error) { return compactG1(proof.Proof.A) }},
+		{"pi_b", func() ([]byte, error) { return compactG2(proof.Proof.B) }},
+		{"pi_c", func() ([]byte, error) { return compactG1(proof.Proof.C) }},
+	} {
+		point, err := p.compress()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidProof, p.name, err)
+		}
+		buf = append(buf, point...)
+	}
+
+	signals := proof.PubSignals
+	buf = binary.AppendUvarint(buf, uint64(len(signals)))
+
+	present := presentSignals(signals, o.omitUnused)
+	if present != nil {
+		buf[1] |= flagSignalsOmitted
+		bitmap := make([]byte, (len(signals)+7)/8)
+		for i, ok := range present {
+			if ok {
+				bitmap[i/8] |= 1 << (i % 8)
+			}
+		}
+		buf = append(buf, bitmap...)
+	}
+
+	for i, s := range signals {
+		if present != nil && !present[i] {
+			continue
+		}
+
+		n, _ := new(big.Int).SetString(s, 10)
+		if n.Cmp(constants.Q) >= 0 {
+			return nil, fmt.Errorf("%w: public signal %d is not in the field", ErrInvalidProof, i)
+		}
+		buf = append(buf, n.FillBytes(make([]byte, fieldSize))...)
+	}
+
+	return buf, nil
+}
+
+// DecodeCompact decodes the proof encoded with EncodeCompact
+func DecodeCompact(data []byte) (zkptypes.ZKProof, error) {
+	const pointsSize = 2 + 2*g1Size + g2Size
+
+	if len(data) == 0 {
+		return zkptypes.ZKProof{}, fmt.Errorf("%w: empty compact proof", ErrInvalidProof)
+	}
+	if data[0] != CompactVersion {
+		return zkptypes.ZKProof{}, fmt.Errorf("%w: %d", ErrUnsupportedVersion, data[0])
+	}
+	if len(data) < pointsSize {
+		return zkptypes.ZKProof{}, fmt.Errorf("%w: compact proof is too short", ErrInvalidProof)
+	}
+
+	flags := data[1]
+	if flags&^flagSignalsOmitted != 0 {
+		return zkptypes.ZKProof{}, fmt.Errorf("%w: unknown flags %08b", ErrInvalidProof, flags)
+	}
+
+	var (
+		proof = zkptypes.ProofData{Protocol: protocolGroth16}
+		err   error
+	)
+	if proof.A, err = expandG1(data[2:]); err != nil {
+		return zkptypes.ZKProof{}, fmt.Errorf("%w: pi_a: %w", ErrInvalidProof, err)
+	}
+	if proof.B, err = expandG2(data[2+g1Size:]); err != nil {
+		return zkptypes.ZKProof{}, fmt.Errorf("%w: pi_b: %w", ErrInvalidProof, err)
+	}
+	if proof.C, err = expandG1(data[2+g1Size+g2Size:]); err != nil {
+		return zkptypes.ZKProof{}, fmt.Errorf("%w: pi_c: %w", ErrInvalidProof, err)
+	}
+
+	data = data[pointsSize:]
+	count, n := binary.Uvarint(data)
+	if n <= 0 || count > uint64(len(data)*8) {
+		return zkptypes.ZKProof{}, fmt.Errorf("%w: invalid signals count", ErrInvalidProof)
+	}
+	data = data[n:]
+
+	var bitmap []byte
+	if flags&flagSignalsOmitted != 0 {
+		if count != uint64(len(unusedSignals)) {
+			return zkptypes.ZKProof{}, fmt.Errorf("%w: signals can be omitted only in the default layout", ErrInvalidProof)
+		}
+		bitmapSize := (int(count) + 7) / 8
+		if len(data) < bitmapSize {
+			return zkptypes.ZKProof{}, fmt.Errorf("%w: compact proof is too short", ErrInvalidProof)
+		}
+		bitmap, data = data[:bitmapSize], data[bitmapSize:]
+	}
+
+	signals := make([]string, count)
+	for i := range signals {
+		if bitmap != nil && bitmap[i/8]&(1<<(i%8)) == 0 {
+			if unusedSignals[i] == nil {
+				return zkptypes.ZKProof{}, fmt.Errorf("%w: public signal %d can't be omitted", ErrInvalidProof, i)
+			}
+			signals[i] = unusedSignals[i].value
+			continue
+		}
+
+		if len(data) < fieldSize {
+			return zkptypes.ZKProof{}, fmt.Errorf("%w: compact proof is too short", ErrInvalidProof)
+		}
+		n := new(big.Int).SetBytes(data[:fieldSize])
+		if n.Cmp(constants.Q) >= 0 {
+			return zkptypes.ZKProof{}, fmt.Errorf("%w: public signal %d is not in the field", ErrInvalidProof, i)
+		}
+		signals[i], data = n.String(), data[fieldSize:]
+	}
+
+	if len(data) != 0 {
+		return zkptypes.ZKProof{}, fmt.Errorf("%w: %d trailing bytes", ErrInvalidProof, len(data))
+	}
+
+	return zkptypes.ZKProof{Proof: &proof, PubSignals: signals}, nil
+}
+
+// EncodeCompactBase64 encodes the proof with EncodeCompact into unpadded
+// base64url, which is safe for URLs and deep links
+func EncodeCompactBase64(proof zkptypes.ZKProof, opts ...CompactOption) (string, error) {
+	data, err := EncodeCompact(proof, opts...)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCompactBase64 decodes the proof encoded with EncodeCompactBase64
+func DecodeCompactBase64(s string) (zkptypes.ZKProof, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return zkptypes.ZKProof{}, fmt.Errorf("%w: base64: %w", ErrInvalidProof, err)
+	}
+	return DecodeCompact(data)
+}
+
+// EncodeCompactBase45 encodes the proof with EncodeCompact into base45 of RFC
+// 9285, which is compact in the alphanumeric mode of QR codes
+func EncodeCompactBase45(proof zkptypes.ZKProof, opts ...CompactOption) (string, error) {
+	data, err := EncodeCompact(proof, opts...)
+	if err != nil {
+		return "", err
+	}
+	return encodeBase45(data), nil
+}
+
+// DecodeCompactBase45 decodes the proof encoded with EncodeCompactBase45
+func DecodeCompactBase45(s string) (zkptypes.ZKProof, error) {
+	data, err := decodeBase45(s)
+	if err != nil {
+		return zkptypes.ZKProof{}, fmt.Errorf("%w: base45: %w", ErrInvalidProof, err)
+	}
+	return DecodeCompact(data)
+}
+
+// presentSignals returns nil when all the signals are written, otherwise it
+// marks the signals to write
+func presentSignals(signals []string, omitUnused bool) []bool {
+	if !omitUnused || len(signals) != len(unusedSignals) {
+		return nil
+	}
+
+	mask, err := kit.ParseSelectorMask(signals[kit.Selector])
+	if err != nil {
+		return nil
+	}
+
+	present := make([]bool, len(signals))
+	for i, s := range signals {
+		unused := unusedSignals[i]
+		present[i] = unused == nil || s != unused.value
+		if present[i] {
+			continue
+		}
+		for _, f := range unused.fields {
+			if mask.Has(f) {
+				present[i] = true
+			}
+		}
+	}
+
+	return present
+}
+
+// compactG1 compresses the normalized [x, y, z] point
+func compactG1(coords []string) ([]byte, error) {
+	p, err := affineG1(coords)
+	if err != nil {
+		return nil, err
+	}
+	if p[0].Sign() == 0 && p[1].Sign() == 0 {
+		return compressG1(nil, nil)
+	}
+	return compressG1(p[0], p[1])
+}
+
+// compactG2 compresses the normalized [[x0, x1], [y0, y1], [z0, z1]] point
+func compactG2(coords [][]string) ([]byte, error) {
+	p, err := affineG2(coords)
+	if err != nil {
+		return nil, err
+	}
+	x, y := fp2(p[0]), fp2(p[1])
+	if x.equal(fp2{new(big.Int), new(big.Int)}) && y.equal(x) {
+		return compressG2(nil, nil)
+	}
+	return compressG2(&x, &y)
+}
+
+// expandG1 decompresses the point into [x, y, 1], or [0, 1, 0] for infinity, as
+// snarkjs outputs them
+func expandG1(buf []byte) ([]string, error) {
+	x, y, err := decompressG1(buf[:g1Size])
+	if err != nil {
+		return nil, err
+	}
+	if x == nil {
+		return []string{"0", "1", "0"}, nil
+	}
+	return []string{x.String(), y.String(), "1"}, nil
+}
+
+// expandG2 decompresses the point into [[x0, x1], [y0, y1], [1, 0]], or
+// [[0, 0], [1, 0], [0, 0]] for infinity, as snarkjs outputs them
+func expandG2(buf []byte) ([][]string, error) {
+	x, y, err := decompressG2(buf[:g2Size])
+	if err != nil {
+		return nil, err
+	}
+	if x == nil {
+		return [][]string{{"0", "0"}, {"1", "0"}, {"0", "0"}}, nil
+	}
+	return [][]string{
+		{x[0].String(), x[1].String()},
+		{y[0].String(), y[1].String()},
+		{"1", "0"},
+	}, nil
+}
@@ -0,0 +1,262 @@
+package proofcodec
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/iden3/go-iden3-crypto/constants"
+	zkptypes "github.com/iden3/go-rapidsnark/types"
+	"github.com/iden3/go-rapidsnark/verifier/bn256"
+	kit "github.com/rarimo/zkverifier-kit"
+	"github.com/rarimo/zkverifier-kit/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompact(t *testing.T) {
+	key, proof := testutil.Groth16Proof(signals)
+	verifier, err := kit.NewPassportVerifier(key,
+		kit.WithProofSelectorValue("23073"),
+		kit.WithNow(time.Date(2024, 5, 24, 0, 0, 0, 0, time.UTC)),
+	)
+	require.NoError(t, err)
+
+	encode := func(opts ...CompactOption) func(zkptypes.ZKProof) (string, error) {
+		return func(p zkptypes.ZKProof) (string, error) {
+			data, err := EncodeCompact(p, opts...)
+			return string(data), err
+		}
+	}
+	decode := func(s string) (zkptypes.ZKProof, error) { return DecodeCompact([]byte(s)) }
+
+	testCases := []struct {
+		name   string
+		encode func(zkptypes.ZKProof) (string, error)
+		decode func(string) (zkptypes.ZKProof, error)
+		size   int
+	}{
+		{name: "Binary", encode: encode(), decode: decode, size: 835},
+		// 12 of 22 signals are omitted with selector 23073
+		{name: "Binary with omitted signals", encode: encode(WithUnusedSignalsOmitted()), decode: decode, size: 454},
+		{name: "Base64", encode: func(p zkptypes.ZKProof) (string, error) { return EncodeCompactBase64(p) }, decode: DecodeCompactBase64, size: 1114},
+		{name: "Base45", encode: func(p zkptypes.ZKProof) (string, error) {
+			return EncodeCompactBase45(p, WithUnusedSignalsOmitted())
+		}, decode: DecodeCompactBase45, size: 681},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			encoded, err := tc.encode(proof)
+			require.NoError(t, err)
+			assert.Len(t, encoded, tc.size)
+
+			decoded, err := tc.decode(encoded)
+			require.NoError(t, err)
+			assert.Equal(t, proof, decoded)
+			assert.NoError(t, verifier.VerifyProof(decoded))
+		})
+	}
+}
+
+func TestCompact_UnusedSignals(t *testing.T) {
+	testCases := []struct {
+		name    string
+		signals func([]string) []string
+		size    int
+	}{
+		{name: "Enabled fields", signals: func(s []string) []string {
+			// birth date is revealed
+			s[kit.Selector] = "23075"
+			return s
+		}, size: 454 + 32},
+		{name: "Non-default values", signals: func(s []string) []string {
+			s[kit.BirthDate] = "0"
+			s[13] = "1"
+			return s
+		}, size: 454 + 64},
+		{name: "Invalid selector", signals: func(s []string) []string {
+			s[kit.Selector] = new(big.Int).Lsh(big.NewInt(1), 70).String()
+			return s
+		}, size: 835},
+		{name: "Custom layout", signals: func(s []string) []string {
+			return s[:21]
+		}, size: 803},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, proof := testutil.Groth16Proof(signals)
+			proof.PubSignals = tc.signals(proof.PubSignals)
+
+			data, err := EncodeCompact(proof, WithUnusedSignalsOmitted())
+			require.NoError(t, err)
+			assert.Len(t, data, tc.size)
+
+			decoded, err := DecodeCompact(data)
+			require.NoError(t, err)
+			assert.Equal(t, proof, decoded)
+		})
+	}
+}
+
+func TestCompact_Points(t *testing.T) {
+	for k := int64(1); k <= 16; k++ {
+		a := g1Strings(new(bn256.G1).ScalarBaseMult(big.NewInt(k)))
+		b := g2Strings(new(bn256.G2).ScalarBaseMult(big.NewInt(k)))
+		proof := zkptypes.ZKProof{
+			Proof:      &zkptypes.ProofData{A: a, B: b, C: a, Protocol: "groth16"},
+			PubSignals: []string{"1"},
+		}
+
+		data, err := EncodeCompact(proof)
+		require.NoError(t, err)
+
+		decoded, err := DecodeCompact(data)
+		require.NoError(t, err)
+		assert.Equal(t, proof, decoded, "k = %d", k)
+	}
+}
+
+func TestCompact_Infinity(t *testing.T) {
+	_, proof := testutil.Groth16Proof(signals)
+	proof.Proof.A = []string{"0", "1", "0"}
+	proof.Proof.B = [][]string{{"0", "0"}, {"1", "0"}, {"0", "0"}}
+
+	data, err := EncodeCompact(proof)
+	require.NoError(t, err)
+	assert.Equal(t, byte(flagInfinity), data[2])
+
+	decoded, err := DecodeCompact(data)
+	require.NoError(t, err)
+	assert.Equal(t, proof, decoded)
+}
+
+func TestEncodeCompact_Errors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		modify func(*zkptypes.ZKProof)
+		want   string
+	}{
+		{name: "Not on curve", modify: func(p *zkptypes.ZKProof) {
+			p.Proof.A[1] = "1"
+		}, want: "pi_a: point is not on curve"},
+		{name: "G2 not on curve", modify: func(p *zkptypes.ZKProof) {
+			p.Proof.B[1][0] = "1"
+		}, want: "pi_b: point is not on curve"},
+		{name: "Coordinate not in field", modify: func(p *zkptypes.ZKProof) {
+			p.Proof.C[0] = fieldP.String()
+		}, want: "pi_c: coordinate is not in the field"},
+		{name: "Not affine", modify: func(p *zkptypes.ZKProof) {
+			p.Proof.C[2] = "2"
+		}, want: "pi_c: point is not affine"},
+		{name: "Signal not in field", modify: func(p *zkptypes.ZKProof) {
+			p.PubSignals[3] = constants.Q.String()
+		}, want: "public signal 3 is not in the field"},
+		{name: "Protocol", modify: func(p *zkptypes.ZKProof) {
+			p.Proof.Protocol = "plonk"
+		}, want: `unsupported protocol "plonk"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, proof := testutil.Groth16Proof(signals)
+			tc.modify(&proof)
+
+			_, err := EncodeCompact(proof)
+			assert.ErrorIs(t, err, ErrInvalidProof)
+			assert.ErrorContains(t, err, tc.want)
+		})
+	}
+}
+
+func TestDecodeCompact_Errors(t *testing.T) {
+	_, proof := testutil.Groth16Proof(signals)
+	data, err := EncodeCompact(proof, WithUnusedSignalsOmitted())
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name   string
+		modify func([]byte) []byte
+		want   error
+		msg    string
+	}{
+		{name: "Empty", modify: func([]byte) []byte { return nil }, want: ErrInvalidProof, msg: "empty"},
+		{name: "Version", modify: func(d []byte) []byte {
+			d[0] = 2
+			return d
+		}, want: ErrUnsupportedVersion, msg: "version: 2"},
+		{name: "Flags", modify: func(d []byte) []byte {
+			d[1] |= 0b10
+			return d
+		}, want: ErrInvalidProof, msg: "unknown flags"},
+		{name: "Uncompressed point", modify: func(d []byte) []byte {
+			d[2] &^= flagMask
+			return d
+		}, want: ErrInvalidProof, msg: "pi_a: point is not compressed"},
+		{name: "Infinity with coordinate", modify: func(d []byte) []byte {
+			d[2+g1Size] = flagInfinity | 1
+			return d
+		}, want: ErrInvalidProof, msg: "pi_b: invalid point at infinity"},
+		{name: "Not on curve", modify: func(d []byte) []byte {
+			// x = 0 is not on the curve, as 3 is not a square
+			copy(d[2+g1Size+g2Size:], make([]byte, g1Size))
+			d[2+g1Size+g2Size] = flagCompressedSmall
+			return d
+		}, want: ErrInvalidProof, msg: "pi_c: point is not on curve"},
+		{name: "Omitted signal", modify: func(d []byte) []byte {
+			// selector is marked as omitted
+			d[2+2*g1Size+g2Size+1+1] &^= 1 << 4
+			return d
+		}, want: ErrInvalidProof, msg: "public signal 12 can't be omitted"},
+		{name: "Truncated", modify: func(d []byte) []byte { return d[:len(d)-1] }, want: ErrInvalidProof, msg: "too short"},
+		{name: "Trailing bytes", modify: func(d []byte) []byte { return append(d, 0) }, want: ErrInvalidProof, msg: "1 trailing bytes"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := DecodeCompact(tc.modify(append([]byte(nil), data...)))
+			assert.ErrorIs(t, err, tc.want)
+			assert.ErrorContains(t, err, tc.msg)
+		})
+	}
+}
+
+func TestBase45(t *testing.T) {
+	// the examples of RFC 9285
+	testCases := []struct {
+		data    string
+		encoded string
+	}{
+		{data: "AB", encoded: "BB8"},
+		{data: "Hello!!", encoded: "%69 VD92EX0"},
+		{data: "base-45", encoded: "UJCLQE7W581"},
+		{data: "ietf!", encoded: "QED8WEX0"},
+		{data: "", encoded: ""},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.encoded, encodeBase45([]byte(tc.data)))
+
+		data, err := decodeBase45(tc.encoded)
+		require.NoError(t, err)
+		assert.Equal(t, tc.data, string(data))
+	}
+
+	for _, invalid := range []string{"GGW", "A", "ab8", "ZZ"} {
+		_, err := decodeBase45(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func g1Strings(p *bn256.G1) []string {
+	m := p.Marshal()
+	return []string{new(big.Int).SetBytes(m[:32]).String(), new(big.Int).SetBytes(m[32:]).String(), "1"}
+}
+
+// g2Strings converts the point into snarkjs order, real parts first
+func g2Strings(p *bn256.G2) [][]string {
+	m := p.Marshal()
+	elem := func(i int) string { return new(big.Int).SetBytes(m[i*32 : (i+1)*32]).String() }
+	return [][]string{{elem(1), elem(0)}, {elem(3), elem(2)}, {"1", "0"}}
+}
@@ -0,0 +1,361 @@
+// Package proofcodec converts the proofs from the formats, which are emitted by
+// mobile clients and on-chain tooling, into zkptypes.ZKProof, that is accepted
+// by Verifier.VerifyProof, and back into Solidity calldata.
+//
+// The supported formats are:
+//   - iden3 JSON: {"proof": {"pi_a": ..., "pi_b": ..., "pi_c": ...}, "pub_signals": [...]}
+//   - snarkjs JSON: the same with "publicSignals" instead of "pub_signals", as
+//     returned by groth16.fullProve, or proof.json and public.json files
+//   - Solidity calldata of verifyProof(a, b, c, input) as printed by snarkjs
+//     zkey export soliditycalldata
+//
+// The numbers can be decimal or 0x-prefixed hex strings in any of the formats,
+// they are normalized to decimal.
+package proofcodec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/iden3/go-iden3-crypto/constants"
+	zkptypes "github.com/iden3/go-rapidsnark/types"
+	kit "github.com/rarimo/zkverifier-kit"
+)
+
+const protocolGroth16 = "groth16"
+
+var ErrInvalidProof = errors.New("invalid proof encoding")
+
+// Format of encoded proof
+type Format int
+
+const (
+	FormatUnknown Format = iota
+	FormatIden3
+	FormatSnarkJS
+	FormatCalldata
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatIden3:
+		return "iden3"
+	case FormatSnarkJS:
+		return "snarkjs"
+	case FormatCalldata:
+		return "calldata"
+	default:
+		return "unknown"
+	}
+}
+
+// Decode detects the format of data and decodes the proof, see DecodeFormat
+func Decode(data []byte) (zkptypes.ZKProof, error) {
+	proof, _, err := DecodeFormat(data)
+	return proof, err
+}
+
+// DecodeFormat detects the format of data, decodes and normalizes the proof.
+// JSON objects are decoded as iden3 or snarkjs proofs, the rest is decoded as
+// calldata.
+func DecodeFormat(data []byte) (zkptypes.ZKProof, Format, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		return decodeJSON(data)
+	}
+
+	proof, err := DecodeCalldata(string(data))
+	return proof, FormatCalldata, err
+}
+
+// DecodeSnarkJS decodes the proof and the public signals from the separate
+// proof.json and public.json files of snarkjs
+func DecodeSnarkJS(proofJSON, publicJSON []byte) (zkptypes.ZKProof, error) {
+	var (
+		proof   zkptypes.ProofData
+		signals []string
+	)
+
+	if err := json.Unmarshal(proofJSON, &proof); err != nil {
+		return zkptypes.ZKProof{}, fmt.Errorf("%w: proof: %w", ErrInvalidProof, err)
+	}
+	if err := json.Unmarshal(publicJSON, &signals); err != nil {
+		return zkptypes.ZKProof{}, fmt.Errorf("%w: public signals: %w", ErrInvalidProof, err)
+	}
+
+	return normalize(zkptypes.ZKProof{Proof: &proof, PubSignals: signals})
+}
+
+// decodeJSON decodes iden3 or snarkjs proof, which differ by the name of the
+// signals field
+func decodeJSON(data []byte) (zkptypes.ZKProof, Format, error) {
+	var raw struct {
+		Proof         *zkptypes.ProofData `json:"proof"`
+		PubSignals    []string            `json:"pub_signals"`
+		PublicSignals []string            `json:"publicSignals"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return zkptypes.ZKProof{}, FormatUnknown, fmt.Errorf("%w: %w", ErrInvalidProof, err)
+	}
+
+	format, signals := FormatIden3, raw.PubSignals
+	if raw.PublicSignals != nil {
+		format, signals = FormatSnarkJS, raw.PublicSignals
+	}
+	if raw.Proof == nil || signals == nil {
+		return zkptypes.ZKProof{}, FormatUnknown, fmt.Errorf("%w: proof and public signals are required", ErrInvalidProof)
+	}
+
+	proof, err := normalize(zkptypes.ZKProof{Proof: raw.Proof, PubSignals: signals})
+	return proof, format, err
+}
+
+// DecodeCalldata decodes the arguments of verifyProof(a, b, c, input) in the
+// format of snarkjs: ["0x..", "0x.."],[["0x..", "0x.."],["0x..", "0x.."]],["0x..", "0x.."],["0x..", ...].
+// The arguments enclosed in square brackets are accepted as well. The
+// coordinates of b are swapped back into snarkjs order, and the [0, 0] points
+// are decoded as the point at infinity, so the round-trip with EncodeCalldata
+// is exact.
+func DecodeCalldata(calldata string) (zkptypes.ZKProof, error) {
+	var args struct {
+		A     [2]string
+		B     [2][2]string
+		C     [2]string
+		Input []string
+	}
+
+	calldata = strings.TrimSpace(calldata)
+	var raw []json.RawMessage
+	if err := json.Unmarshal([]byte("["+calldata+"]"), &raw); err != nil {
+		return zkptypes.ZKProof{}, fmt.Errorf("%w: calldata: %w", ErrInvalidProof, err)
+	}
+	if len(raw) == 1 {
+		// the arguments are already enclosed in brackets
+		if err := json.Unmarshal(raw[0], &raw); err != nil {
+			return zkptypes.ZKProof{}, fmt.Errorf("%w: calldata: %w", ErrInvalidProof, err)
+		}
+	}
+	if len(raw) != 4 {
+		return zkptypes.ZKProof{}, fmt.Errorf("%w: calldata must have 4 arguments, got %d", ErrInvalidProof, len(raw))
+	}
+
+	for i, dst := range []any{&args.A, &args.B, &args.C, &args.Input} {
+		if err := json.Unmarshal(raw[i], dst); err != nil {
+			return zkptypes.ZKProof{}, fmt.Errorf("%w: calldata argument %d: %w", ErrInvalidProof, i, err)
+		}
+	}
+
+	proof, err := normalize(zkptypes.ZKProof{
+		Proof: &zkptypes.ProofData{
+			A: []string{args.A[0], args.A[1], "1"},
+			B: [][]string{
+				{args.B[0][1], args.B[0][0]},
+				{args.B[1][1], args.B[1][0]},
+				{"1", "0"},
+			},
+			C:        []string{args.C[0], args.C[1], "1"},
+			Protocol: protocolGroth16,
+		},
+		PubSignals: args.Input,
+	})
+	if err != nil {
+		return proof, err
+	}
+
+	for _, g1 := range []*[]string{&proof.Proof.A, &proof.Proof.C} {
+		if (*g1)[0] == "0" && (*g1)[1] == "0" {
+			*g1 = []string{"0", "1", "0"}
+		}
+	}
+	if b := proof.Proof.B; b[0][0] == "0" && b[0][1] == "0" && b[1][0] == "0" && b[1][1] == "0" {
+		proof.Proof.B = [][]string{{"0", "0"}, {"1", "0"}, {"0", "0"}}
+	}
+
+	return proof, nil
+}
+
+// EncodeCalldata encodes the proof into the arguments of verifyProof(a, b, c,
+// input) in the format of snarkjs, so the same proof can be submitted
+// on-chain. The points must be affine, i.e. have z = 1, as snarkjs outputs
+// them, or be the point at infinity, which is encoded as [0, 0]. The
+// coordinates must be in the base field and the signals in the scalar field.
+func EncodeCalldata(proof zkptypes.ZKProof) (string, error) {
+	if proof.Proof == nil {
+		return "", fmt.Errorf("%w: proof is missing", ErrInvalidProof)
+	}
+
+	a, err := affineG1(proof.Proof.A)
+	if err != nil {
+		return "", fmt.Errorf("%w: pi_a: %w", ErrInvalidProof, err)
+	}
+	b, err := affineG2(proof.Proof.B)
+	if err != nil {
+		return "", fmt.Errorf("%w: pi_b: %w", ErrInvalidProof, err)
+	}
+	c, err := affineG1(proof.Proof.C)
+	if err != nil {
+		return "", fmt.Errorf("%w: pi_c: %w", ErrInvalidProof, err)
+	}
+
+	inputs := make([]string, len(proof.PubSignals))
+	for i, s := range proof.PubSignals {
+		n, err := kit.ParseFieldElement(s)
+		if err != nil {
+			return "", fmt.Errorf("%w: public signal %d: %w", ErrInvalidProof, i, err)
+		}
+		if n.Cmp(constants.Q) >= 0 {
+			return "", fmt.Errorf("%w: public signal %d is not in the field", ErrInvalidProof, i)
+		}
+		inputs[i] = p256(n)
+	}
+
+	return fmt.Sprintf("[%s, %s],[[%s, %s],[%s, %s]],[%s, %s],[%s]",
+		p256(a[0]), p256(a[1]),
+		p256(b[0][1]), p256(b[0][0]), p256(b[1][1]), p256(b[1][0]),
+		p256(c[0]), p256(c[1]),
+		strings.Join(inputs, ","),
+	), nil
+}
+
+// normalize converts all the numbers to decimal and checks the shape of the
+// points
+func normalize(proof zkptypes.ZKProof) (zkptypes.ZKProof, error) {
+	p := proof.Proof
+	if p == nil {
+		return proof, fmt.Errorf("%w: proof is missing", ErrInvalidProof)
+	}
+
+	if len(p.A) != 3 || len(p.C) != 3 {
+		return proof, fmt.Errorf("%w: pi_a and pi_c must have 3 coordinates", ErrInvalidProof)
+	}
+	if len(p.B) != 3 || len(p.B[0]) != 2 || len(p.B[1]) != 2 || len(p.B[2]) != 2 {
+		return proof, fmt.Errorf("%w: pi_b must have 3 pairs of coordinates", ErrInvalidProof)
+	}
+
+	normalized := zkptypes.ZKProof{
+		Proof: &zkptypes.ProofData{
+			B:        make([][]string, 3),
+			Protocol: p.Protocol,
+		},
+		PubSignals: make([]string, len(proof.PubSignals)),
+	}
+	if normalized.Proof.Protocol == "" {
+		normalized.Proof.Protocol = protocolGroth16
+	}
+
+	var err error
+	if normalized.Proof.A, err = decimals(p.A); err != nil {
+		return proof, fmt.Errorf("%w: pi_a: %w", ErrInvalidProof, err)
+	}
+	for i := range p.B {
+		if normalized.Proof.B[i], err = decimals(p.B[i]); err != nil {
+			return proof, fmt.Errorf("%w: pi_b: %w", ErrInvalidProof, err)
+		}
+	}
+	if normalized.Proof.C, err = decimals(p.C); err != nil {
+		return proof, fmt.Errorf("%w: pi_c: %w", ErrInvalidProof, err)
+	}
+	if normalized.PubSignals, err = decimals(proof.PubSignals); err != nil {
+		return proof, fmt.Errorf("%w: public signals: %w", ErrInvalidProof, err)
+	}
+
+	return normalized, nil
+}
+
+func decimals(values []string) ([]string, error) {
+	res := make([]string, len(values))
+	for i, v := range values {
+		n, err := kit.ParseFieldElement(v)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = n.String()
+	}
+	return res, nil
+}
+
+// affineG1 returns x and y of [x, y, z] point with z = 1, or zeros for the
+// point at infinity
+func affineG1(coords []string) ([2]*big.Int, error) {
+	var res [2]*big.Int
+	if len(coords) != 3 {
+		return res, fmt.Errorf("G1 point must have 3 coordinates, got %d", len(coords))
+	}
+
+	z, err := kit.ParseFieldElement(coords[2])
+	if err != nil {
+		return res, err
+	}
+
+	switch {
+	case z.Sign() == 0:
+		return [2]*big.Int{new(big.Int), new(big.Int)}, nil
+	case z.Cmp(big.NewInt(1)) != 0:
+		return res, errors.New("point is not affine")
+	}
+
+	for i := range res {
+		if res[i], err = kit.ParseFieldElement(coords[i]); err != nil {
+			return res, err
+		}
+		if res[i].Cmp(fieldP) >= 0 {
+			return res, errors.New("coordinate is not in the field")
+		}
+	}
+
+	return res, nil
+}
+
+// affineG2 returns x and y of [x, y, z] point with z = [1, 0], or zeros for
+// the point at infinity. The coordinates are in snarkjs order, real first.
+func affineG2(coords [][]string) ([2][2]*big.Int, error) {
+	var res [2][2]*big.Int
+	if len(coords) != 3 {
+		return res, fmt.Errorf("G2 point must have 3 coordinates, got %d", len(coords))
+	}
+
+	for i, c := range coords {
+		if len(c) != 2 {
+			return res, fmt.Errorf("G2 coordinate %d must have 2 elements, got %d", i, len(c))
+		}
+	}
+
+	z0, err := kit.ParseFieldElement(coords[2][0])
+	if err != nil {
+		return res, err
+	}
+	z1, err := kit.ParseFieldElement(coords[2][1])
+	if err != nil {
+		return res, err
+	}
+
+	switch {
+	case z0.Sign() == 0 && z1.Sign() == 0:
+		zero := new(big.Int)
+		return [2][2]*big.Int{{zero, zero}, {zero, zero}}, nil
+	case z0.Cmp(big.NewInt(1)) != 0 || z1.Sign() != 0:
+		return res, errors.New("point is not affine")
+	}
+
+	for i := range res {
+		for j := range res[i] {
+			if res[i][j], err = kit.ParseFieldElement(coords[i][j]); err != nil {
+				return res, err
+			}
+			if res[i][j].Cmp(fieldP) >= 0 {
+				return res, errors.New("coordinate is not in the field")
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// p256 formats the number as quoted 32-byte hex, as snarkjs does
+func p256(n *big.Int) string {
+	return fmt.Sprintf(`"0x%064x"`, n)
+}
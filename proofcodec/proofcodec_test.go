@@ -0,0 +1,216 @@
+package proofcodec
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	zkptypes "github.com/iden3/go-rapidsnark/types"
+	kit "github.com/rarimo/zkverifier-kit"
+	"github.com/rarimo/zkverifier-kit/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signals are the public signals of a passport proof with selector 23073
+var signals = []string{
+	"304358862882731539112827930982999386691702727710421481944329166126417129570",
+	"52983525027888", "52983525027888", "0", "0", "0", "5589842", "0", "0",
+	"304358862882731539112827930982999386691702727710421481944329166126417129570",
+	"14393086243856018838405247242117964464658357003864077561407424514652280923159",
+	"14393086243856018838405247242117964464658357003864077561407424514652280923159",
+	"23073", "0", "1713436478", "0", "1", "52983525027888",
+	"53009295159860", "55199728480820", "52983525027888", "0",
+}
+
+func TestDecode(t *testing.T) {
+	key, proof := testutil.Groth16Proof(signals)
+	verifier, err := kit.NewPassportVerifier(key,
+		kit.WithProofSelectorValue("23073"),
+		kit.WithNow(time.Date(2024, 5, 24, 0, 0, 0, 0, time.UTC)),
+	)
+	require.NoError(t, err)
+
+	calldata, err := EncodeCalldata(proof)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name   string
+		data   string
+		format Format
+	}{
+		{name: "iden3", data: iden3JSON(t, proof), format: FormatIden3},
+		{name: "snarkjs", data: snarkJSON(t, proof, false), format: FormatSnarkJS},
+		{name: "snarkjs with hex", data: snarkJSON(t, proof, true), format: FormatSnarkJS},
+		{name: "calldata", data: calldata, format: FormatCalldata},
+		{name: "calldata in brackets", data: "\n[" + calldata + "]\n", format: FormatCalldata},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			decoded, format, err := DecodeFormat([]byte(tc.data))
+			require.NoError(t, err)
+			assert.Equal(t, tc.format, format)
+			assert.Equal(t, proof, decoded)
+			assert.NoError(t, verifier.VerifyProof(decoded))
+		})
+	}
+}
+
+func TestDecodeSnarkJS(t *testing.T) {
+	_, proof := testutil.Groth16Proof(signals)
+
+	proofJSON, err := json.Marshal(proof.Proof)
+	require.NoError(t, err)
+	publicJSON, err := json.Marshal(proof.PubSignals)
+	require.NoError(t, err)
+
+	decoded, err := DecodeSnarkJS(proofJSON, publicJSON)
+	require.NoError(t, err)
+	assert.Equal(t, proof, decoded)
+}
+
+func TestEncodeCalldata(t *testing.T) {
+	_, proof := testutil.Groth16Proof(signals)
+
+	calldata, err := EncodeCalldata(proof)
+	require.NoError(t, err)
+
+	// b coordinates are swapped: imaginary part first
+	b := proof.Proof.B
+	assert.True(t, strings.HasPrefix(calldata, fmt.Sprintf("[%s, %s],[[%s, %s],", hex(proof.Proof.A[0]), hex(proof.Proof.A[1]), hex(b[0][1]), hex(b[0][0]))))
+	assert.True(t, strings.HasSuffix(calldata, fmt.Sprintf(",%s]", hex(signals[len(signals)-1]))))
+
+	decoded, err := DecodeCalldata(calldata)
+	require.NoError(t, err)
+	assert.Equal(t, proof, decoded)
+}
+
+func TestDecode_Errors(t *testing.T) {
+	_, proof := testutil.Groth16Proof(signals)
+	calldata, err := EncodeCalldata(proof)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "Empty", data: "", want: "calldata"},
+		{name: "Missing signals", data: `{"proof": {"pi_a": ["1", "2", "1"]}}`, want: "public signals are required"},
+		{name: "Short point", data: `{"proof": {"pi_a": ["1", "2"], "pi_b": [], "pi_c": []}, "pub_signals": []}`, want: "3 coordinates"},
+		{name: "Invalid number", data: strings.Replace(iden3JSON(t, proof), signals[0], "0xZZ", 1), want: `invalid field element "zz"`},
+		{name: "Negative number", data: strings.Replace(iden3JSON(t, proof), signals[0], "-1", 1), want: `invalid field element "-1"`},
+		{name: "Calldata arguments", data: calldata[:strings.LastIndex(calldata, ",[")], want: "4 arguments"},
+		{name: "Calldata point", data: strings.Replace(calldata, "[[", "[", 1), want: "calldata"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Decode([]byte(tc.data))
+			assert.ErrorIs(t, err, ErrInvalidProof)
+			assert.ErrorContains(t, err, tc.want)
+		})
+	}
+}
+
+func TestEncodeCalldata_Projective(t *testing.T) {
+	_, proof := testutil.Groth16Proof(signals)
+	proof.Proof.A = []string{proof.Proof.A[0], proof.Proof.A[1], "2"}
+
+	_, err := EncodeCalldata(proof)
+	assert.ErrorContains(t, err, "pi_a: point is not affine")
+
+	proof.Proof.A = []string{"0", "1", "0"}
+	proof.Proof.B = [][]string{{"0", "0"}, {"1", "0"}, {"0", "0"}}
+	calldata, err := EncodeCalldata(proof)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(calldata, fmt.Sprintf("[%s, %s]", hex("0"), hex("0"))))
+
+	decoded, err := DecodeCalldata(calldata)
+	require.NoError(t, err)
+	assert.Equal(t, proof, decoded)
+}
+
+func TestEncodeCalldata_Field(t *testing.T) {
+	_, valid := testutil.Groth16Proof(signals)
+
+	testCases := []struct {
+		name   string
+		modify func(p *zkptypes.ZKProof)
+		want   string
+	}{
+		{
+			name:   "Coordinate",
+			modify: func(p *zkptypes.ZKProof) { p.Proof.A = []string{fieldP.String(), p.Proof.A[1], "1"} },
+			want:   "pi_a: coordinate is not in the field",
+		},
+		{
+			name: "G2 coordinate",
+			modify: func(p *zkptypes.ZKProof) {
+				p.Proof.B = [][]string{p.Proof.B[0], {p.Proof.B[1][0], fieldP.String()}, p.Proof.B[2]}
+			},
+			want: "pi_b: coordinate is not in the field",
+		},
+		{
+			name:   "Signal",
+			modify: func(p *zkptypes.ZKProof) { p.PubSignals = []string{fieldP.String()} },
+			want:   "public signal 0 is not in the field",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			proof := valid
+			proof.Proof = &zkptypes.ProofData{A: valid.Proof.A, B: valid.Proof.B, C: valid.Proof.C, Protocol: valid.Proof.Protocol}
+			tc.modify(&proof)
+
+			_, err := EncodeCalldata(proof)
+			assert.ErrorIs(t, err, ErrInvalidProof)
+			assert.ErrorContains(t, err, tc.want)
+		})
+	}
+}
+
+func iden3JSON(t *testing.T, proof zkptypes.ZKProof) string {
+	raw, err := json.Marshal(proof)
+	require.NoError(t, err)
+	return string(raw)
+}
+
+// snarkJSON encodes the proof as snarkjs groth16.fullProve result, optionally
+// with hex-encoded numbers
+func snarkJSON(t *testing.T, proof zkptypes.ZKProof, withHex bool) string {
+	encode := func(values []string) []string {
+		if !withHex {
+			return values
+		}
+		res := make([]string, len(values))
+		for i, v := range values {
+			n, _ := new(big.Int).SetString(v, 10)
+			res[i] = "0x" + n.Text(16)
+		}
+		return res
+	}
+
+	raw, err := json.Marshal(map[string]any{
+		"proof": map[string]any{
+			"pi_a":     encode(proof.Proof.A),
+			"pi_b":     [][]string{encode(proof.Proof.B[0]), encode(proof.Proof.B[1]), encode(proof.Proof.B[2])},
+			"pi_c":     encode(proof.Proof.C),
+			"protocol": "groth16",
+			"curve":    "bn128",
+		},
+		"publicSignals": encode(proof.PubSignals),
+	})
+	require.NoError(t, err)
+	return string(raw)
+}
+
+func hex(decimal string) string {
+	n, _ := new(big.Int).SetString(decimal, 10)
+	return fmt.Sprintf(`"0x%064x"`, n)
+}
@@ -0,0 +1,137 @@
+package zkverifier_kit
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// SelectorField is a bit position in proof selector. Each enabled bit makes the
+// circuit reveal or constrain the corresponding passport field.
+type SelectorField uint
+
+const (
+	SelectorNullifier SelectorField = iota
+	SelectorBirthDate
+	SelectorExpirationDate
+	SelectorName
+	SelectorNationality
+	SelectorCitizenship
+	SelectorSex
+	SelectorDocumentNumber
+	SelectorTimestampLowerBound
+	SelectorTimestampUpperBound
+	SelectorIdentityCounterLowerBound
+	SelectorIdentityCounterUpperBound
+	SelectorExpirationDateLowerBound
+	SelectorExpirationDateUpperBound
+	SelectorBirthDateLowerBound
+	SelectorBirthDateUpperBound
+	SelectorCitizenshipWhitelist
+	SelectorCitizenshipBlacklist
+)
+
+var selectorFieldNames = [...]string{
+	SelectorNullifier:                 "nullifier",
+	SelectorBirthDate:                 "birth_date",
+	SelectorExpirationDate:            "expiration_date",
+	SelectorName:                      "name",
+	SelectorNationality:               "nationality",
+	SelectorCitizenship:               "citizenship",
+	SelectorSex:                       "sex",
+	SelectorDocumentNumber:            "document_number",
+	SelectorTimestampLowerBound:       "timestamp_lower_bound",
+	SelectorTimestampUpperBound:       "timestamp_upper_bound",
+	SelectorIdentityCounterLowerBound: "identity_counter_lower_bound",
+	SelectorIdentityCounterUpperBound: "identity_counter_upper_bound",
+	SelectorExpirationDateLowerBound:  "expiration_date_lower_bound",
+	SelectorExpirationDateUpperBound:  "expiration_date_upper_bound",
+	SelectorBirthDateLowerBound:       "birth_date_lower_bound",
+	SelectorBirthDateUpperBound:       "birth_date_upper_bound",
+	SelectorCitizenshipWhitelist:      "citizenship_whitelist",
+	SelectorCitizenshipBlacklist:      "citizenship_blacklist",
+}
+
+func (f SelectorField) String() string {
+	if int(f) < len(selectorFieldNames) {
+		return selectorFieldNames[f]
+	}
+	return fmt.Sprintf("SelectorField(%d)", uint(f))
+}
+
+// SelectorMask is a decoded Selector public signal
+type SelectorMask uint64
+
+// ParseSelectorMask decodes selector from a decimal string. Bits beyond the
+// known SelectorField values are rejected.
+func ParseSelectorMask(selector string) (SelectorMask, error) {
+	b, ok := new(big.Int).SetString(selector, 10)
+	if !ok || b.Sign() < 0 {
+		return 0, fmt.Errorf("invalid selector %q: must be a non-negative decimal integer", selector)
+	}
+
+	if b.BitLen() > len(selectorFieldNames) {
+		return 0, fmt.Errorf("invalid selector %q: unknown bits are set", selector)
+	}
+
+	return SelectorMask(b.Uint64()), nil
+}
+
+// Has checks whether the field is enabled in selector
+func (m SelectorMask) Has(f SelectorField) bool {
+	return m&(1<<f) != 0
+}
+
+// Fields lists all the enabled fields
+func (m SelectorMask) Fields() []SelectorField {
+	var fields []SelectorField
+	for f := range selectorFieldNames {
+		if m.Has(SelectorField(f)) {
+			fields = append(fields, SelectorField(f))
+		}
+	}
+	return fields
+}
+
+func (m SelectorMask) String() string {
+	fields := m.Fields()
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.String()
+	}
+	return strings.Join(names, "|")
+}
+
+// signalFields are the selector bits, which the circuit enables when it
+// populates the signals. The bits of the birth date bounds are crossed: the app
+// proofs with selector 23073 enable SelectorBirthDateLowerBound and carry the
+// age limit in BirthdateUpperBound signal.
+var signalFields = map[PubSignal]SelectorField{
+	Nullifier:                 SelectorNullifier,
+	BirthDate:                 SelectorBirthDate,
+	ExpirationDate:            SelectorExpirationDate,
+	Citizenship:               SelectorCitizenship,
+	TimestampUpperBound:       SelectorTimestampUpperBound,
+	IdentityCounterUpperBound: SelectorIdentityCounterUpperBound,
+	BirthdateLowerBound:       SelectorBirthDateUpperBound,
+	BirthdateUpperBound:       SelectorBirthDateLowerBound,
+	ExpirationDateLowerBound:  SelectorExpirationDateLowerBound,
+}
+
+// SelectorField returns the selector bit, which makes the circuit populate the
+// signal. The signals, which are always populated, e.g. EventID, have none.
+func (s PubSignal) SelectorField() (SelectorField, bool) {
+	f, ok := signalFields[s]
+	return f, ok
+}
+
+// requireSelected returns an error if the field of the signal, required by the
+// option, is not enabled in selector
+func requireSelected(mask SelectorMask, option string, signal PubSignal) error {
+	field := signalFields[signal]
+	if mask.Has(field) {
+		return nil
+	}
+
+	return &fieldNotSelectedError{option: option, field: field, mask: mask}
+}
@@ -0,0 +1,81 @@
+package zkverifier_kit
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSelectorMask(t *testing.T) {
+	mask, err := ParseSelectorMask("23073")
+	require.NoError(t, err)
+	assert.Equal(t, []SelectorField{
+		SelectorNullifier,
+		SelectorCitizenship,
+		SelectorTimestampUpperBound,
+		SelectorIdentityCounterUpperBound,
+		SelectorExpirationDateLowerBound,
+		SelectorBirthDateLowerBound,
+	}, mask.Fields())
+	assert.True(t, mask.Has(SelectorCitizenship))
+	assert.False(t, mask.Has(SelectorBirthDate))
+
+	for _, invalid := range []string{"", "0x5a21", "-1", "262144"} {
+		_, err = ParseSelectorMask(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestVerifyProof_Selector(t *testing.T) {
+	testCases := []struct {
+		name    string
+		disable SelectorField
+		opts    []VerifyOption
+		want    string
+	}{
+		{
+			name:    "Citizenship is not selected",
+			disable: SelectorCitizenship,
+			opts:    []VerifyOption{WithCitizenships(ukrCitizenship)},
+			want:    "pub_signals/citizenship: option WithCitizenships requires field citizenship, but selector does not enable it",
+		},
+		{
+			// the circuit enables the lower bound bit for the upper bound signal
+			name:    "Birth date upper bound is not selected",
+			disable: SelectorBirthDateLowerBound,
+			opts:    []VerifyOption{WithAgeAbove(equalAge)},
+			want:    "pub_signals/birth_date_upper_bound: option WithAgeAbove requires field birth_date_lower_bound, but selector does not enable it",
+		},
+		{
+			// the timestamp is checked, because the counter fails
+			name:    "Identity timestamp is not selected",
+			disable: SelectorTimestampUpperBound,
+			opts:    []VerifyOption{WithIdentitiesCounter(0), WithIdentitiesCreationTimestampLimit(maxTimestamp)},
+			want:    "pub_signals/timestamp_upper_bound: option WithIdentitiesCreationTimestampLimit requires field timestamp_upper_bound, but selector does not enable it",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mask, err := ParseSelectorMask(validProof.PubSignals[Selector])
+			require.NoError(t, err)
+			selector := strconv.FormatUint(uint64(mask&^(1<<tc.disable)), 10)
+
+			proof := validProof
+			proof.PubSignals = slices.Clone(validProof.PubSignals)
+			proof.PubSignals[Selector] = selector
+
+			opts := append([]VerifyOption{WithNow(proofDate), WithProofSelectorValue(selector)}, tc.opts...)
+			// groth16 verification always fails with this key, which shows that
+			// the signals are valid
+			invalidKey := mismatchedKey(t, 1)
+			verifier, err := NewPassportVerifier(invalidKey, opts...)
+			require.NoError(t, err)
+
+			assert.ErrorContains(t, verifier.VerifyProof(proof), tc.want)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package zkverifier_kit
+
+import (
+	"context"
+	"errors"
+
+	val "github.com/go-ozzo/ozzo-validation/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const tracerName = "github.com/rarimo/zkverifier-kit"
+
+// WithTracerProvider enables OpenTelemetry tracing of proof verification: the
+// zkverifier.VerifyProof span has the child spans of signal validation and
+// Groth16 verification, and identity.Verifier creates the span of the contract
+// call inside the validation. The failed checks are recorded as span events.
+// Tracing is disabled by default.
+func WithTracerProvider(provider trace.TracerProvider) VerifyOption {
+	return func(opts *VerifyOptions) {
+		opts.tracer = provider.Tracer(tracerName)
+	}
+}
+
+var noopTracer = noop.NewTracerProvider().Tracer(tracerName)
+
+// startSpan starts the span with the configured tracer
+func (v *Verifier) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return v.opts.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan records the error and ends the span. Each failed check of val.Errors is
+// recorded as "check failed" event with the field and the code, the other
+// errors are recorded as exceptions.
+func endSpan(span trace.Span, err error) {
+	defer span.End()
+	if err == nil || !span.IsRecording() {
+		return
+	}
+
+	var errs val.Errors
+	if !errors.As(err, &errs) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+
+	for _, field := range sortedKeys(errs) {
+		if errs[field] == nil {
+			continue
+		}
+
+		attrs := []attribute.KeyValue{
+			attribute.String("field", field),
+			attribute.String("message", errs[field].Error()),
+		}
+		var verr *VerificationError
+		if errors.As(errs[field], &verr) {
+			attrs = append(attrs, attribute.String("code", string(verr.Code)))
+		}
+		span.AddEvent("check failed", trace.WithAttributes(attrs...))
+	}
+	span.SetStatus(codes.Error, "proof is invalid")
+}
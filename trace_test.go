@@ -0,0 +1,133 @@
+package zkverifier_kit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rarimo/zkverifier-kit/identity"
+	"github.com/rarimo/zkverifier-kit/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestWithTracerProvider(t *testing.T) {
+	key, proof := testutil.Groth16Proof(validProof.PubSignals)
+	contract := common.HexToAddress("0x10F370A6d8a5E7ef2a0F1a3E1d1f9A8A2Ff8bd5E")
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	verifier, err := NewPassportVerifier(key,
+		WithNow(proofDate),
+		WithProofSelectorValue(validSelector),
+		WithTracerProvider(provider),
+		WithIdentityVerifier(identity.NewVerifier(new(testutil.MockCaller).WithRoot(storedRoot), 0).
+			WithEndpoint("https://rpc.example.com/v3/api-key", contract)),
+	)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name   string
+		opts   []VerifyOption
+		spans  []string
+		failed map[string]string
+	}{
+		{
+			name:  "Valid proof",
+			spans: []string{"identity.VerifyRoot", "zkverifier.ValidateSignals", "zkverifier.VerifyGroth16", "zkverifier.VerifyProof"},
+		},
+		{
+			name:   "Invalid proof",
+			opts:   []VerifyOption{WithCitizenships("USA"), WithEventID(invalidEventID)},
+			spans:  []string{"identity.VerifyRoot", "zkverifier.ValidateSignals", "zkverifier.VerifyProof"},
+			failed: map[string]string{"pub_signals/citizenship": "citizenship_not_allowed", "pub_signals/event_id": "event_id_mismatch"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			exporter.Reset()
+			err := verifier.VerifyProofContext(context.Background(), proof, tc.opts...)
+			assert.Equal(t, tc.failed == nil, err == nil)
+
+			spans := exporter.GetSpans()
+			require.Len(t, spans, len(tc.spans))
+
+			byName := make(map[string]tracetest.SpanStub)
+			for i, span := range spans {
+				assert.Equal(t, tc.spans[i], span.Name)
+				byName[span.Name] = span
+			}
+
+			// the spans form a single trace under zkverifier.VerifyProof
+			root := byName["zkverifier.VerifyProof"]
+			assert.False(t, root.Parent.IsValid())
+			assert.Equal(t, byName["zkverifier.ValidateSignals"].SpanContext.SpanID(), byName["identity.VerifyRoot"].Parent.SpanID())
+			assert.Equal(t, root.SpanContext.SpanID(), byName["zkverifier.ValidateSignals"].Parent.SpanID())
+			if groth16, ok := byName["zkverifier.VerifyGroth16"]; ok {
+				assert.Equal(t, root.SpanContext.SpanID(), groth16.Parent.SpanID())
+			}
+
+			attrs := attribute.NewSet(byName["identity.VerifyRoot"].Attributes...)
+			endpoint, _ := attrs.Value("rpc.endpoint")
+			assert.Equal(t, "https://rpc.example.com", endpoint.AsString())
+			address, _ := attrs.Value("contract.address")
+			assert.Equal(t, contract.Hex(), address.AsString())
+
+			if tc.failed == nil {
+				assert.Equal(t, codes.Unset, root.Status.Code)
+				return
+			}
+
+			for _, span := range []tracetest.SpanStub{root, byName["zkverifier.ValidateSignals"]} {
+				assert.Equal(t, codes.Error, span.Status.Code)
+
+				failed := make(map[string]string)
+				for _, event := range span.Events {
+					require.Equal(t, "check failed", event.Name)
+					attrs := attribute.NewSet(event.Attributes...)
+					field, _ := attrs.Value("field")
+					code, _ := attrs.Value("code")
+					failed[field.AsString()] = code.AsString()
+				}
+				assert.Equal(t, tc.failed, failed)
+			}
+		})
+	}
+}
+
+func TestWithTracerProvider_ContractCall(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	verifier, err := NewPassportVerifier(verificationKey,
+		WithProofSelectorValue(validSelector),
+		WithTracerProvider(provider),
+		WithIdentityVerifier(identity.NewVerifier(failingCaller{}, 0)),
+	)
+	require.NoError(t, err)
+
+	err = verifier.VerifyProof(validProof)
+	require.ErrorIs(t, err, identity.ErrContractCall)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+	for _, span := range spans {
+		assert.Equal(t, codes.Error, span.Status.Code, span.Name)
+		require.NotEmpty(t, span.Events, span.Name)
+		assert.Equal(t, "exception", span.Events[0].Name, span.Name)
+	}
+}
+
+type failingCaller struct{}
+
+func (failingCaller) IsRootValid(*bind.CallOpts, [32]byte) (bool, error) {
+	return false, errors.New("connection refused")
+}
@@ -15,6 +15,7 @@ type (
 
 	timeRule struct {
 		point       time.Time
+		decode      zkDateDecoder
 		isBefore    bool
 		isEqualDate bool
 	}
@@ -39,14 +40,9 @@ func (r timeRule) Validate(date interface{}) error {
 		return fmt.Errorf("invalid type: %T, expected string", date)
 	}
 
-	bigDecimalDate, ok := new(big.Int).SetString(raw, 10)
-	if !ok {
-		return fmt.Errorf("failed to set string: %T", date)
-	}
-
-	parsed, err := time.Parse("060102", string(bigDecimalDate.Bytes()))
+	parsed, err := r.decode(raw)
 	if err != nil {
-		return fmt.Errorf("invalid date string: %w", err)
+		return err
 	}
 
 	if r.isEqualDate {
@@ -71,25 +67,44 @@ func datesEqual(one time.Time, another time.Time) bool {
 	return one.Format(time.DateOnly) == another.Format(time.DateOnly)
 }
 
-func beforeDate(point time.Time) timeRule {
+// earlierDate returns the earlier of non-zero dates
+func earlierDate(one, another time.Time) time.Time {
+	if one.IsZero() || another.Before(one) {
+		return another
+	}
+	return one
+}
+
+// laterDate returns the later of non-zero dates
+func laterDate(one, another time.Time) time.Time {
+	if one.IsZero() || another.After(one) {
+		return another
+	}
+	return one
+}
+
+func beforeDate(point time.Time, decode zkDateDecoder) timeRule {
 	return timeRule{
 		point:       point,
+		decode:      decode,
 		isBefore:    true,
 		isEqualDate: false,
 	}
 }
 
-func afterDate(point time.Time) timeRule {
+func afterDate(point time.Time, decode zkDateDecoder) timeRule {
 	return timeRule{
 		point:       point,
+		decode:      decode,
 		isBefore:    false,
 		isEqualDate: false,
 	}
 }
 
-func equalDate(point time.Time) timeRule {
+func equalDate(point time.Time, decode zkDateDecoder) timeRule {
 	return timeRule{
 		point:       point,
+		decode:      decode,
 		isBefore:    false,
 		isEqualDate: true,
 	}
@@ -111,3 +126,12 @@ func validateOnOptSet(value, option any, rules val.Rule) error {
 		rules,
 	))
 }
+
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package zkverifier_kit
 
 import (
+	"context"
+
 	zkptypes "github.com/iden3/go-rapidsnark/types"
 	"gitlab.com/distributed_lab/logan/v3"
 	"gitlab.com/distributed_lab/logan/v3/errors"
@@ -15,6 +17,8 @@ var ErrUnknownProofType = errors.New("unknown proof type")
 // Connector is an abstraction which collects all the methods to be implemented by each verifier
 type Connector interface {
 	VerifyProof(zkptypes.ZKProof, ...VerifyOption) error
+	VerifyProofContext(context.Context, zkptypes.ZKProof, ...VerifyOption) error
+	VerifyProofs(context.Context, []zkptypes.ZKProof, ...VerifyOption) []Result
 }
 
 // NewVerifier is a general constructor that will create a new verifier instance depending on
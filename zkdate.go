@@ -0,0 +1,121 @@
+package zkverifier_kit
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// EmptyZKDate is the value of date signals, which are not revealed by the
+// proof. It is the encoding of "000000". Some circuits use "0" as well, see
+// IsEmptyZKDate.
+const EmptyZKDate = "52983525027888"
+
+const zkDateLayout = "060102"
+
+// EncodeZKDate encodes the date into public signal: a big decimal integer,
+// which bytes are ASCII digits in format YYMMDD. The century is lost, so the
+// decoding requires the reference time, see DecodeBirthDate and
+// DecodeExpirationDate.
+func EncodeZKDate(date time.Time) string {
+	return new(big.Int).SetBytes([]byte(date.Format(zkDateLayout))).String()
+}
+
+// IsEmptyZKDate reports whether the date signal is not used or is not present
+// in selector. ZKP sets such dates to 0 or EmptyZKDate.
+func IsEmptyZKDate(raw string) bool {
+	return raw == "0" || raw == EmptyZKDate
+}
+
+// DecodeBirthDate decodes the date, which can't be in the future: the year is
+// resolved into the 100 years, which end on the date of now. For example,
+// "550101" is 1955-01-01 when now is in 2024.
+func DecodeBirthDate(raw string, now time.Time) (time.Time, error) {
+	yy, month, day, err := zkDateDigits(raw)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	year := latestYear(now.Year(), yy)
+	if year == now.Year() && (month > now.Month() || month == now.Month() && day > now.Day()) {
+		year -= 100
+	}
+
+	return zkDate(raw, year, month, day)
+}
+
+// DecodeExpirationDate decodes the date of document expiration: the year is
+// resolved into the 50 years after now, unless the date is within the 50 years
+// before now, so that the expired documents stay expired. For example,
+// "340101" is 2034-01-01 and "990101" is 1999-01-01 when now is in 2024.
+func DecodeExpirationDate(raw string, now time.Time) (time.Time, error) {
+	yy, month, day, err := zkDateDigits(raw)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	year := latestYear(now.Year(), yy)
+	if now.Year()-year >= 50 {
+		year += 100
+	}
+
+	return zkDate(raw, year, month, day)
+}
+
+// zkDateDecoder decodes the date relative to the reference time
+type zkDateDecoder func(raw string) (time.Time, error)
+
+func birthDateDecoder(now time.Time) zkDateDecoder {
+	return func(raw string) (time.Time, error) {
+		return DecodeBirthDate(raw, now)
+	}
+}
+
+func expirationDateDecoder(now time.Time) zkDateDecoder {
+	return func(raw string) (time.Time, error) {
+		return DecodeExpirationDate(raw, now)
+	}
+}
+
+// decodeDate returns the decoded date when possible, otherwise the raw value, it
+// is useful for error reports
+func decodeDate(raw string, decode zkDateDecoder) any {
+	if date, err := decode(raw); err == nil {
+		return date
+	}
+	return raw
+}
+
+// zkDateDigits decodes a date from big decimal integer, which bytes are ASCII
+// digits in format YYMMDD
+func zkDateDigits(raw string) (yy int, month time.Month, day int, err error) {
+	bigDecimalDate, ok := new(big.Int).SetString(raw, 10)
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("failed to set string: %q", raw)
+	}
+
+	// the year is parsed only to validate the digits, the century is resolved
+	// by the caller
+	parsed, err := time.Parse(zkDateLayout, string(bigDecimalDate.Bytes()))
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid date string: %w", err)
+	}
+
+	return parsed.Year() % 100, parsed.Month(), parsed.Day(), nil
+}
+
+// zkDate builds the date, rejecting February 29 in the resolved year, if it
+// is not a leap one
+func zkDate(raw string, year int, month time.Month, day int) (time.Time, error) {
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	if date.Day() != day {
+		return time.Time{}, fmt.Errorf("invalid date string: %q is not a valid date in %d", decodeInt(raw), year)
+	}
+	return date, nil
+}
+
+// latestYear returns the latest year, not after the given one, which ends
+// with the two digits yy
+func latestYear(year, yy int) int {
+	return year - ((year%100-yy)%100+100)%100
+}
@@ -0,0 +1,65 @@
+package zkverifier_kit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeZKDate(t *testing.T) {
+	now := time.Date(2024, 5, 24, 12, 0, 0, 0, time.UTC)
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	testCases := []struct {
+		name       string
+		raw        string
+		birth      time.Time
+		expiration time.Time
+		want       string
+	}{
+		{name: "Last century", raw: EncodeZKDate(date(1955, 1, 1)), birth: date(1955, 1, 1), expiration: date(2055, 1, 1)},
+		{name: "This century", raw: EncodeZKDate(date(2000, 1, 1)), birth: date(2000, 1, 1), expiration: date(2000, 1, 1)},
+		{name: "Today", raw: EncodeZKDate(now), birth: date(2024, 5, 24), expiration: date(2024, 5, 24)},
+		{name: "Tomorrow", raw: EncodeZKDate(now.AddDate(0, 0, 1)), birth: date(1924, 5, 25), expiration: date(2024, 5, 25)},
+		{name: "Recently expired", raw: EncodeZKDate(date(2019, 12, 31)), birth: date(2019, 12, 31), expiration: date(2019, 12, 31)},
+		{name: "Expiration window edge", raw: EncodeZKDate(date(2073, 1, 1)), birth: date(1973, 1, 1), expiration: date(2073, 1, 1)},
+		{name: "Expired long ago", raw: EncodeZKDate(date(1975, 1, 1)), birth: date(1975, 1, 1), expiration: date(1975, 1, 1)},
+		{name: "Leap day of 2000", raw: EncodeZKDate(date(2000, 2, 29)), birth: date(2000, 2, 29), expiration: date(2000, 2, 29)},
+		{name: "Not a number", raw: "date", want: "failed to set string"},
+		{name: "Not a date", raw: "1", want: "invalid date string"},
+		{name: "Empty date", raw: EmptyZKDate, want: "invalid date string"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			birth, birthErr := DecodeBirthDate(tc.raw, now)
+			expiration, expirationErr := DecodeExpirationDate(tc.raw, now)
+			if tc.want != "" {
+				assert.ErrorContains(t, birthErr, tc.want)
+				assert.ErrorContains(t, expirationErr, tc.want)
+				return
+			}
+
+			require.NoError(t, birthErr)
+			require.NoError(t, expirationErr)
+			assert.Equal(t, tc.birth, birth)
+			assert.Equal(t, tc.expiration, expiration)
+		})
+	}
+}
+
+func TestDecodeZKDate_LeapDay(t *testing.T) {
+	// 1900 is not a leap year, unlike 2000
+	_, err := DecodeBirthDate(EncodeZKDate(time.Date(2000, 2, 29, 0, 0, 0, 0, time.UTC)), time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.ErrorContains(t, err, "not a valid date in 1900")
+}
+
+func TestIsEmptyZKDate(t *testing.T) {
+	assert.True(t, IsEmptyZKDate("0"))
+	assert.True(t, IsEmptyZKDate(EmptyZKDate))
+	assert.False(t, IsEmptyZKDate(EncodeZKDate(time.Now())))
+}